@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/credential"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/fabricgateway"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+)
+
+// handleExportCredential signs a training record as committed on the ledger with the caller's
+// identity, and attaches the block holding the transaction as proof it was committed
+func handleExportCredential(ctx context.Context, gateway *fabricgateway.Gateway, chaincodeName string, client *personnelclient.PersonnelClient, args []string) {
+	flags := flag.NewFlagSet("export-credential", flag.ExitOnError)
+	chainPath := flags.String("chain", "", "PEM file of the certificates that issued the signing identity's, intermediates then root")
+	outPath := flags.String("out", "", "file to write, <record-id>.credential.json when not set")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		fmt.Println("Error: record-id is required")
+		fmt.Println("Usage: go run . export-credential [--chain <file.pem>] [--out <file>] <record-id>")
+		os.Exit(1)
+	}
+	recordID := flags.Arg(0)
+
+	signer, err := gateway.Signer()
+	if err != nil {
+		log.Fatalf("failed to load signing identity: %v", err)
+	}
+
+	chain := []*x509.Certificate{signer.Certificate}
+	if *chainPath != "" {
+		data, err := os.ReadFile(*chainPath)
+		if err != nil {
+			log.Fatalf("failed to read certificate chain: %v", err)
+		}
+		issuers, err := credential.ReadCertificates(data)
+		if err != nil {
+			log.Fatalf("failed to read certificate chain: %v", err)
+		}
+		for _, issuer := range issuers {
+			if !issuer.Equal(signer.Certificate) {
+				chain = append(chain, issuer)
+			}
+		}
+	}
+
+	revision, err := client.GetTrainingRevision(ctx, recordID)
+	if err != nil {
+		log.Fatalf("failed to get training record: %v", err)
+	}
+
+	network, err := gateway.GetNetwork(ctx)
+	if err != nil {
+		log.Fatalf("failed to get network: %v", err)
+	}
+
+	block, err := personnelclient.NewLedger(network).BlockByTransactionID(ctx, revision.TransactionID)
+	if err != nil {
+		log.Fatalf("failed to find block for transaction %s: %v", revision.TransactionID, err)
+	}
+
+	proof, err := credential.NewProof(block, revision.TransactionID)
+	if err != nil {
+		log.Fatalf("failed to build ledger proof: %v", err)
+	}
+
+	issued, err := credential.Issue(credential.Claims{
+		Training:      revision.Training,
+		TransactionID: revision.TransactionID,
+		BlockNumber:   block.GetHeader().GetNumber(),
+		ChannelName:   network.Name(),
+		ChaincodeName: chaincodeName,
+		IssuerMSPID:   signer.MSPID,
+		IssuedAt:      time.Now().UTC().Format(time.RFC3339),
+	}, chain, signer.Sign)
+	if err != nil {
+		log.Fatalf("failed to issue credential: %v", err)
+	}
+	issued.Proof = proof
+
+	output, err := json.MarshalIndent(issued, "", "  ")
+	if err != nil {
+		log.Fatalf("failed to marshal credential: %v", err)
+	}
+
+	if *outPath == "" {
+		*outPath = recordID + ".credential.json"
+	}
+	if err := os.WriteFile(*outPath, append(output, '\n'), 0o644); err != nil {
+		log.Fatalf("failed to write credential: %v", err)
+	}
+
+	fmt.Printf("Credential for %s written to %s\n", recordID, *outPath)
+	fmt.Printf("  Transaction: %s\n", revision.TransactionID)
+	fmt.Printf("  Block:       %d (%x), transaction %d of %d\n", proof.BlockHeader.Number, proof.BlockHash, proof.TransactionIndex+1, len(proof.BlockData))
+	fmt.Printf("  Signed by:   %s (%s)\n", signer.Certificate.Subject, signer.MSPID)
+}
+
+// handleVerifyCredential checks a credential without contacting a peer, exiting 1 if it is invalid
+// and 2 if it is intact but its signer could not be traced to a trusted root
+func handleVerifyCredential(args []string) {
+	flags := flag.NewFlagSet("verify-credential", flag.ExitOnError)
+	rootsPath := flags.String("roots", "", "PEM file of trusted root certificates, the issuing organisation's CAs")
+	endorsersList := flags.String("endorsers", "", "comma separated MSP IDs that must have endorsed the transaction, each needs --endorser-roots")
+	blockHashHex := flags.String("block-hash", "", "hex hash of the credential's block from a trusted peer, the next block's previous hash")
+	endorserRoots := map[string]*x509.CertPool{}
+	flags.Func("endorser-roots", "<msp-id>=<file.pem> root certificates of an endorsing organisation, repeatable", func(value string) error {
+		mspID, path, ok := strings.Cut(value, "=")
+		if !ok || mspID == "" || path == "" {
+			return fmt.Errorf("expected <msp-id>=<file.pem>")
+		}
+		roots, err := readRoots(path)
+		if err != nil {
+			return err
+		}
+		endorserRoots[mspID] = roots
+		return nil
+	})
+	flags.Parse(args)
+
+	var endorsers []string
+	for _, mspID := range strings.Split(*endorsersList, ",") {
+		if mspID = strings.TrimSpace(mspID); mspID != "" {
+			endorsers = append(endorsers, mspID)
+		}
+	}
+
+	if flags.NArg() < 1 {
+		fmt.Println("Error: credential file is required")
+		fmt.Println("Usage: go run . verify-credential [--roots <file.pem>] [--endorser-roots <msp-id>=<file.pem>]... [--endorsers <msp-id,...>] [--block-hash <hex>] <credential.json>")
+		os.Exit(1)
+	}
+
+	for _, mspID := range endorsers {
+		if endorserRoots[mspID] == nil {
+			fmt.Printf("Error: --endorsers %s needs --endorser-roots %s=<file.pem>, an MSP ID alone proves nothing\n", mspID, mspID)
+			os.Exit(1)
+		}
+	}
+
+	var blockHash []byte
+	if *blockHashHex != "" {
+		decoded, err := hex.DecodeString(*blockHashHex)
+		if err != nil {
+			fmt.Printf("Error: --block-hash must be hex: %v\n", err)
+			os.Exit(1)
+		}
+		blockHash = decoded
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		log.Fatalf("failed to read credential: %v", err)
+	}
+
+	var presented credential.Credential
+	if err := json.Unmarshal(data, &presented); err != nil {
+		fmt.Printf("INVALID: credential is not valid JSON: %v\n", err)
+		os.Exit(1)
+	}
+
+	var roots *x509.CertPool
+	if *rootsPath != "" {
+		roots, err = readRoots(*rootsPath)
+		if err != nil {
+			log.Fatalf("failed to read trusted roots: %v", err)
+		}
+	}
+
+	result, err := credential.Verify(&presented, roots)
+	if err != nil {
+		fmt.Printf("INVALID: %v\n", err)
+		os.Exit(1)
+	}
+
+	claims := result.Claims
+
+	var proof *credential.ProofResult
+	if presented.Proof != nil {
+		proof, err = credential.VerifyProof(claims, presented.Proof, credential.ProofOptions{
+			EndorserRoots: endorserRoots,
+			Endorsers:     endorsers,
+			BlockHash:     blockHash,
+		})
+		if err != nil {
+			fmt.Printf("INVALID: ledger proof: %v\n", err)
+			os.Exit(1)
+		}
+	} else if len(endorsers) > 0 {
+		fmt.Println("INVALID: credential has no ledger proof to show who endorsed it")
+		os.Exit(1)
+	}
+
+	verdict := "VALID"
+	if !result.Anchored {
+		verdict = "UNVERIFIED"
+	}
+
+	fmt.Printf("%s credential\n", verdict)
+	fmt.Printf("  Record:       %s\n", claims.Training.RecordID)
+	fmt.Printf("  Personnel:    %s\n", claims.Training.PersonnelID)
+	fmt.Printf("  Training:     %s\n", claims.Training.TrainingCode)
+	fmt.Printf("  Completed At: %s\n", claims.Training.CompletedAt)
+	fmt.Printf("  Issued By:    %s\n", claims.Training.IssuedBy)
+	fmt.Printf("  Transaction:  %s\n", claims.TransactionID)
+	fmt.Printf("  Block:        %d\n", claims.BlockNumber)
+	fmt.Printf("  Channel:      %s/%s\n", claims.ChannelName, claims.ChaincodeName)
+	fmt.Printf("  Signed by:    %s (%s)\n", result.Signer.Subject, claims.IssuerMSPID)
+	fmt.Printf("  Signed at:    %s\n", claims.IssuedAt)
+
+	if proof != nil {
+		fmt.Printf("\nLedger proof\n")
+		fmt.Printf("  Block:        %d\n", proof.BlockNumber)
+		fmt.Printf("  Block hash:   %x\n", proof.BlockHash)
+		fmt.Printf("  Previous:     %x\n", proof.PreviousHash)
+		fmt.Printf("  Function:     %s\n", proof.Function)
+		fmt.Printf("  Validation:   %s (unverified, from block metadata the block hash does not cover)\n", proof.ValidationCode)
+		for _, endorsement := range proof.Endorsements {
+			trust := "not anchored"
+			if endorsement.Anchored {
+				trust = "trusted by " + endorsement.MSPID + "'s roots"
+			}
+			fmt.Printf("  Endorsed by:  %s (%s, %s)\n", endorsement.Certificate.Subject, endorsement.MSPID, trust)
+		}
+	}
+
+	if proof == nil {
+		fmt.Println("\nWarning: the credential has no ledger proof, only the signer vouches that the record was committed")
+	} else if proof.OnChain {
+		fmt.Printf("\nBlock %d matches the trusted --block-hash\n", proof.BlockNumber)
+	} else {
+		fmt.Printf("\nWarning: block %d is not confirmed to be on the channel, pass block %d's previous hash from a trusted peer as --block-hash\n", proof.BlockNumber, proof.BlockNumber+1)
+	}
+
+	if !result.Anchored {
+		fmt.Println("\nUNVERIFIED: no --roots given, the signature is intact but the signer's certificate is not proven to belong to the issuing organisation")
+		os.Exit(2)
+	}
+}
+
+// readRoots loads a PEM file of trusted root certificates
+func readRoots(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	certificates, err := credential.ReadCertificates(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	roots := x509.NewCertPool()
+	for _, certificate := range certificates {
+		roots.AddCert(certificate)
+	}
+	return roots, nil
+}
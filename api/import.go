@@ -0,0 +1,469 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+)
+
+// defaultBatchSize keeps each batch transaction well under the chaincode's limit of 500 entries
+const defaultBatchSize = 100
+
+// csvRow is one data row of an import file, line is its line number for error messages
+type csvRow struct {
+	line   int
+	fields map[string]string
+}
+
+func handleImportCadets(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: file is required")
+		fmt.Println("Usage: go run . import-cadets <file.csv> [batch-size] [from-line]")
+		os.Exit(1)
+	}
+
+	batchSize := defaultBatchSize
+	if len(args) > 1 {
+		size, err := strconv.Atoi(args[1])
+		if err != nil || size < 1 {
+			fmt.Println("Error: batch-size must be a positive number")
+			fmt.Println("Usage: go run . import-cadets <file.csv> [batch-size] [from-line]")
+			os.Exit(1)
+		}
+		batchSize = size
+	}
+
+	// fromLine resumes an import stopped by a rejected batch, earlier lines are still checked but not submitted
+	fromLine := 0
+	if len(args) > 2 {
+		line, err := strconv.Atoi(args[2])
+		if err != nil || line < 1 {
+			fmt.Println("Error: from-line must be a positive number")
+			fmt.Println("Usage: go run . import-cadets <file.csv> [batch-size] [from-line]")
+			os.Exit(1)
+		}
+		fromLine = line
+	}
+
+	rows, err := readCSV(args[0], "personnelID", "name", "campus")
+	if err != nil {
+		log.Fatalf("failed to read %s: %v", args[0], err)
+	}
+
+	// The whole file is checked first, as each batch is its own transaction and a bad row found
+	// part way through would leave the earlier batches enrolled
+	cadets := make([]domain.CadetEnrolment, len(rows))
+	firstLine := map[string]int{}
+	var problems []string
+	for i, row := range rows {
+		cadets[i] = domain.CadetEnrolment{
+			PersonnelID: row.fields["personnelID"],
+			Name:        row.fields["name"],
+			Campus:      row.fields["campus"],
+		}
+
+		if err := personnelclient.ValidateEnrolment(cadets[i]); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", row.line, err))
+			continue
+		}
+		if first, duplicate := firstLine[cadets[i].PersonnelID]; duplicate {
+			problems = append(problems, fmt.Sprintf("line %d: personnel ID %s is duplicated (first on line %d)", row.line, cadets[i].PersonnelID, first))
+			continue
+		}
+		firstLine[cadets[i].PersonnelID] = row.line
+	}
+
+	if len(problems) > 0 {
+		fmt.Printf("Import rejected, nothing was submitted:\n")
+		for _, problem := range problems {
+			fmt.Printf("  %s\n", problem)
+		}
+		os.Exit(1)
+	}
+
+	// Batches are submitted in file order and the import stops at the first rejected one, so the
+	// cadets enrolled are always the lines before it and a re-run can resume from there
+	first := len(rows)
+	for i, row := range rows {
+		if row.line >= fromLine {
+			first = i
+			break
+		}
+	}
+
+	var enrolled int
+	for start := first; start < len(cadets); start += batchSize {
+		end := min(start+batchSize, len(cadets))
+
+		result, err := client.EnrollCadetsBatch(ctx, cadets[start:end])
+		if err != nil {
+			log.Fatalf("failed to enroll lines %d-%d, the lines before %d were enrolled: %v", rows[start].line, rows[end-1].line, rows[start].line, err)
+		}
+
+		if result.Committed {
+			enrolled += end - start
+			fmt.Printf("  lines %d-%d: enrolled %d cadets\n", rows[start].line, rows[end-1].line, end-start)
+			continue
+		}
+
+		fmt.Printf("  lines %d-%d: batch rejected, none enrolled\n", rows[start].line, rows[end-1].line)
+		fmt.Printf("Import stopped:\n")
+		fmt.Printf("  Enrolled:     %d\n", enrolled)
+		fmt.Printf("  Not enrolled: %d\n", len(cadets)-start)
+		fmt.Printf("Rejected lines (the other lines of the batch were skipped):\n")
+		for _, entry := range result.Results {
+			if entry.Status == domain.BatchEntryRejected {
+				fmt.Printf("  line %d: %s\n", rows[start+entry.Index].line, entry.Error)
+			}
+		}
+		fmt.Printf("Fix the rejected lines, then resume with:\n")
+		fmt.Printf("  go run . import-cadets %s %d %d\n", args[0], batchSize, rows[start].line)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Import finished:\n")
+	fmt.Printf("  Enrolled:     %d\n", enrolled)
+	if first > 0 {
+		fmt.Printf("  Skipped:      %d (before line %d)\n", first, fromLine)
+	}
+}
+
+// trainingColumns are the fields of an import-training row, the CSV header or JSON keys
+var trainingColumns = []string{"recordID", "personnelID", "campus", "trainingCode", "completedAt", "issuedBy"}
+
+// trainingRow is one row of an import-training file and what became of it
+type trainingRow struct {
+	line       int
+	completion domain.TrainingCompletion
+	status     string
+	err        string
+}
+
+func handleImportTraining(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: file is required")
+		fmt.Println("Usage: go run . import-training <file.csv|file.json|file.jsonl> [batch-size] [report-file]")
+		os.Exit(1)
+	}
+
+	path := args[0]
+
+	batchSize := defaultBatchSize
+	if len(args) > 1 {
+		size, err := strconv.Atoi(args[1])
+		if err != nil || size < 1 {
+			fmt.Println("Error: batch-size must be a positive number")
+			fmt.Println("Usage: go run . import-training <file.csv|file.json|file.jsonl> [batch-size] [report-file]")
+			os.Exit(1)
+		}
+		batchSize = size
+	}
+
+	reportPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".report.csv"
+	if len(args) > 2 {
+		reportPath = args[2]
+	}
+
+	rows, err := readTrainingFile(path)
+	if err != nil {
+		log.Fatalf("failed to read %s: %v", path, err)
+	}
+
+	// Rows failing the client-side rules are rejected here and never submitted
+	var pending []*trainingRow
+	firstLine := map[string]int{}
+	for _, row := range rows {
+		if row.status != "" {
+			continue
+		}
+		if err := personnelclient.ValidateTraining(row.completion); err != nil {
+			row.status, row.err = domain.BatchEntryRejected, err.Error()
+			continue
+		}
+		if first, duplicate := firstLine[row.completion.RecordID]; duplicate {
+			row.status, row.err = domain.BatchEntryRejected, fmt.Sprintf("recordID %s is duplicated (first on line %d)", row.completion.RecordID, first)
+			continue
+		}
+		firstLine[row.completion.RecordID] = row.line
+		pending = append(pending, row)
+	}
+
+	for start := 0; start < len(pending); start += batchSize {
+		batch := pending[start:min(start+batchSize, len(pending))]
+
+		completions := make([]domain.TrainingCompletion, len(batch))
+		for i, row := range batch {
+			completions[i] = row.completion
+		}
+
+		result, err := client.CompleteTrainingBatch(ctx, completions)
+		if err != nil {
+			// The report still records the earlier batches, which were committed
+			if reportErr := writeTrainingReport(reportPath, rows); reportErr != nil {
+				log.Fatalf("failed to submit lines %d-%d: %v (the report could not be written either: %v)", batch[0].line, batch[len(batch)-1].line, err, reportErr)
+			}
+			log.Fatalf("failed to submit lines %d-%d: %v (report of the earlier batches: %s)", batch[0].line, batch[len(batch)-1].line, err, reportPath)
+		}
+
+		written := 0
+		for _, entry := range result.Results {
+			batch[entry.Index].status, batch[entry.Index].err = entry.Status, entry.Error
+			if entry.Status == domain.BatchEntryWritten {
+				written++
+			}
+		}
+		fmt.Printf("  lines %d-%d: recorded %d of %d\n", batch[0].line, batch[len(batch)-1].line, written, len(batch))
+	}
+
+	if err := writeTrainingReport(reportPath, rows); err != nil {
+		log.Fatalf("failed to write report: %v", err)
+	}
+
+	var recorded, rejected int
+	for _, row := range rows {
+		if row.status == domain.BatchEntryWritten {
+			recorded++
+		} else {
+			rejected++
+		}
+	}
+
+	fmt.Printf("Import finished:\n")
+	fmt.Printf("  Recorded: %d\n", recorded)
+	fmt.Printf("  Rejected: %d\n", rejected)
+	fmt.Printf("  Report:   %s\n", reportPath)
+
+	if rejected > 0 {
+		os.Exit(1)
+	}
+}
+
+// readTrainingFile reads CSV, a JSON array when the file is named .json, or JSON lines when it is
+// named .jsonl/.ndjson. Other files are read as a JSON array when they start with [, JSON lines when
+// they start with {, and CSV otherwise.
+func readTrainingFile(path string) ([]*trainingRow, error) {
+	format := "csv"
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = "json"
+	case ".jsonl", ".ndjson":
+		format = "jsonl"
+	case ".csv":
+	default:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		content := strings.TrimSpace(string(data))
+		if strings.HasPrefix(content, "[") {
+			format = "json"
+		} else if strings.HasPrefix(content, "{") {
+			format = "jsonl"
+		}
+	}
+
+	switch format {
+	case "json":
+		return readTrainingJSONArray(path)
+	case "jsonl":
+		return readTrainingJSONLines(path)
+	}
+
+	records, err := readCSV(path, trainingColumns...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*trainingRow, len(records))
+	for i, record := range records {
+		rows[i] = &trainingRow{
+			line: record.line,
+			completion: domain.TrainingCompletion{
+				RecordID:     record.fields["recordID"],
+				PersonnelID:  record.fields["personnelID"],
+				Campus:       record.fields["campus"],
+				TrainingCode: record.fields["trainingCode"],
+				CompletedAt:  record.fields["completedAt"],
+				IssuedBy:     record.fields["issuedBy"],
+			},
+		}
+	}
+
+	return rows, nil
+}
+
+// readTrainingJSONLines reads one JSON object per line, blank lines are ignored
+func readTrainingJSONLines(path string) ([]*trainingRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rows []*trainingRow
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		row := &trainingRow{line: line}
+		if err := json.Unmarshal([]byte(text), &row.completion); err != nil {
+			// Kept as a rejected row so the report accounts for every line of the file
+			row.status, row.err = domain.BatchEntryRejected, fmt.Sprintf("invalid JSON: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file has no rows")
+	}
+
+	return rows, nil
+}
+
+// readTrainingJSONArray reads a JSON array of rows, each row's line is where its object starts
+func readTrainingJSONArray(path string) ([]*trainingRow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, fmt.Errorf("file is not a JSON array of rows, name JSON lines files .jsonl")
+	}
+
+	var rows []*trainingRow
+	for decoder.More() {
+		var raw json.RawMessage
+		offset := decoder.InputOffset()
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON after line %d: %w", lineAt(data, offset), err)
+		}
+
+		// The offset is that of the previous token, so the row starts at the first byte of raw after it
+		start := offset + int64(bytes.Index(data[offset:], raw))
+		row := &trainingRow{line: lineAt(data, start)}
+		if err := json.Unmarshal(raw, &row.completion); err != nil {
+			// Kept as a rejected row so the report accounts for every row of the file
+			row.status, row.err = domain.BatchEntryRejected, fmt.Sprintf("invalid row: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file has no rows")
+	}
+
+	return rows, nil
+}
+
+// lineAt is the 1-based line of the byte at offset
+func lineAt(data []byte, offset int64) int {
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// writeTrainingReport writes the outcome of every row of the import file as CSV
+func writeTrainingReport(path string, rows []*trainingRow) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"line", "recordID", "personnelID", "trainingCode", "status", "error"})
+	for _, row := range rows {
+		status := row.status
+		if status == "" {
+			status = "not submitted"
+		}
+		writer.Write([]string{strconv.Itoa(row.line), row.completion.RecordID, row.completion.PersonnelID, row.completion.TrainingCode, status, row.err})
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// readCSV reads a CSV file whose header row names the columns, in any order and case. Every
+// required column must be present.
+func readCSV(path string, required ...string) ([]csvRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	indexes := make([]int, len(required))
+	for i, name := range required {
+		index, ok := columns[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("header is missing column %s (required: %s)", name, strings.Join(required, ","))
+		}
+		indexes[i] = index
+	}
+
+	var rows []csvRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := csvRow{
+			line:   line,
+			fields: map[string]string{},
+		}
+		for i, name := range required {
+			row.fields[name] = strings.TrimSpace(record[indexes[i]])
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file has no rows")
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,168 @@
+package blockreplay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// Document types written by the chaincode's repositories
+const (
+	DocTypePersonnel = "personnel"
+	DocTypeTraining  = "training"
+)
+
+// Write is one world state write made by the chaincode. Only document keys, <docType>:<id>, are
+// kept; composite index keys are left out.
+type Write struct {
+	DocType  string
+	ID       string
+	Value    []byte
+	IsDelete bool
+}
+
+// Transaction holds the document writes of one valid transaction, in the order they were made
+type Transaction struct {
+	BlockNumber   uint64
+	TransactionID string
+	Writes        []Write
+}
+
+// DecodeBlock returns the valid endorser transactions in block that wrote documents in the
+// chaincode's namespace. Invalid transactions are skipped as their writes never reached world state.
+func DecodeBlock(block *common.Block, chaincodeName string) ([]*Transaction, error) {
+	blockNumber := block.GetHeader().GetNumber()
+
+	var validationCodes []byte
+	if metadata := block.GetMetadata().GetMetadata(); len(metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		validationCodes = metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+
+	var transactions []*Transaction
+	for i, envelopeBytes := range block.GetData().GetData() {
+		if i >= len(validationCodes) || peer.TxValidationCode(validationCodes[i]) != peer.TxValidationCode_VALID {
+			continue
+		}
+
+		transaction, err := DecodeEnvelope(envelopeBytes, chaincodeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode transaction %d of block %d: %w", i, blockNumber, err)
+		}
+		if transaction == nil || len(transaction.Writes) == 0 {
+			continue
+		}
+
+		transaction.BlockNumber = blockNumber
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// DecodeEnvelope returns the document writes of one transaction envelope regardless of whether it
+// was valid, or nil for anything other than an endorser transaction, such as config updates
+func DecodeEnvelope(envelopeBytes []byte, chaincodeName string) (*Transaction, error) {
+	envelope := &common.Envelope{}
+	if err := proto.Unmarshal(envelopeBytes, envelope); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal envelope: %w", err)
+	}
+
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.GetPayload(), payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), channelHeader); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal channel header: %w", err)
+	}
+	if common.HeaderType(channelHeader.GetType()) != common.HeaderType_ENDORSER_TRANSACTION {
+		return nil, nil
+	}
+
+	transaction := &peer.Transaction{}
+	if err := proto.Unmarshal(payload.GetData(), transaction); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+	}
+
+	result := &Transaction{TransactionID: channelHeader.GetTxId()}
+	for _, action := range transaction.GetActions() {
+		writes, err := decodeAction(action, chaincodeName)
+		if err != nil {
+			return nil, err
+		}
+		result.Writes = append(result.Writes, writes...)
+	}
+
+	return result, nil
+}
+
+func decodeAction(action *peer.TransactionAction, chaincodeName string) ([]Write, error) {
+	actionPayload := &peer.ChaincodeActionPayload{}
+	if err := proto.Unmarshal(action.GetPayload(), actionPayload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chaincode action payload: %w", err)
+	}
+
+	responsePayload := &peer.ProposalResponsePayload{}
+	if err := proto.Unmarshal(actionPayload.GetAction().GetProposalResponsePayload(), responsePayload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proposal response payload: %w", err)
+	}
+
+	chaincodeAction := &peer.ChaincodeAction{}
+	if err := proto.Unmarshal(responsePayload.GetExtension(), chaincodeAction); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chaincode action: %w", err)
+	}
+
+	readWriteSet := &rwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(chaincodeAction.GetResults(), readWriteSet); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal read/write set: %w", err)
+	}
+
+	var writes []Write
+	for _, namespace := range readWriteSet.GetNsRwset() {
+		// Lifecycle and system chaincode writes share the set
+		if namespace.GetNamespace() != chaincodeName {
+			continue
+		}
+
+		kvSet := &kvrwset.KVRWSet{}
+		if err := proto.Unmarshal(namespace.GetRwset(), kvSet); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal key/value set: %w", err)
+		}
+
+		for _, write := range kvSet.GetWrites() {
+			docType, id, ok := documentKey(write.GetKey())
+			if !ok {
+				continue
+			}
+
+			writes = append(writes, Write{
+				DocType:  docType,
+				ID:       id,
+				Value:    write.GetValue(),
+				IsDelete: write.GetIsDelete(),
+			})
+		}
+	}
+
+	return writes, nil
+}
+
+// documentKey splits a repository key, composite keys start with a null byte so never match
+func documentKey(key string) (string, string, bool) {
+	if strings.HasPrefix(key, "\x00") {
+		return "", "", false
+	}
+
+	docType, id, ok := strings.Cut(key, ":")
+	if !ok || docType == "" || id == "" {
+		return "", "", false
+	}
+
+	return docType, id, true
+}
@@ -0,0 +1,70 @@
+// Package blockreplay reads the channel's blocks and decodes the chaincode's world state writes,
+// so off-chain stores can be rebuilt from the ledger itself rather than from chaincode events.
+package blockreplay
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// BlockHandler is called once per block in order, including blocks without matching writes, so
+// the handler can checkpoint every block
+type BlockHandler func(ctx context.Context, blockNumber uint64, transactions []*Transaction) error
+
+// Replayer reads full blocks, filtered blocks do not carry the read/write sets
+type Replayer struct {
+	network       *client.Network
+	chaincodeName string
+}
+
+func NewReplayer(network *client.Network, chaincodeName string) *Replayer {
+	return &Replayer{
+		network:       network,
+		chaincodeName: chaincodeName,
+	}
+}
+
+// Replay calls handle for every block from startBlock to endBlock inclusive, stopping at the first error
+func (r *Replayer) Replay(ctx context.Context, startBlock, endBlock uint64, handle BlockHandler) error {
+	if startBlock > endBlock {
+		return fmt.Errorf("start block %d is after end block %d", startBlock, endBlock)
+	}
+
+	// Block events never end on their own, cancelling stops the stream once endBlock is reached
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	blocks, err := r.network.BlockEvents(ctx, client.WithStartBlock(startBlock))
+	if err != nil {
+		return fmt.Errorf("failed to start block event listening: %w", err)
+	}
+
+	next := startBlock
+	for block := range blocks {
+		blockNumber := block.GetHeader().GetNumber()
+		if blockNumber != next {
+			return fmt.Errorf("expected block %d but received %d", next, blockNumber)
+		}
+
+		transactions, err := DecodeBlock(block, r.chaincodeName)
+		if err != nil {
+			return err
+		}
+
+		if err := handle(ctx, blockNumber, transactions); err != nil {
+			return err
+		}
+
+		if blockNumber == endBlock {
+			return nil
+		}
+		next++
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("block stream ended before block %d", next)
+}
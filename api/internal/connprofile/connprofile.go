@@ -0,0 +1,231 @@
+package connprofile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is the subset of a Hyperledger Fabric common connection profile needed to reach a gateway peer.
+// Both the YAML and JSON forms produced by network tooling are accepted.
+type Profile struct {
+	Name          string                  `yaml:"name"`
+	Client        Client                  `yaml:"client"`
+	Organizations map[string]Organization `yaml:"organizations"`
+	Peers         map[string]Peer         `yaml:"peers"`
+
+	// dir is used to resolve relative certificate paths
+	dir string
+}
+
+type Client struct {
+	Organization string `yaml:"organization"`
+}
+
+type Organization struct {
+	MSPID string   `yaml:"mspid"`
+	Peers []string `yaml:"peers"`
+}
+
+type Peer struct {
+	URL         string         `yaml:"url"`
+	TLSCACerts  TLSCACerts     `yaml:"tlsCACerts"`
+	GRPCOptions map[string]any `yaml:"grpcOptions"`
+}
+
+type TLSCACerts struct {
+	PEM  string `yaml:"pem"`
+	Path string `yaml:"path"`
+}
+
+// Endpoint is everything needed to dial one peer as a member of one organization
+type Endpoint struct {
+	Organization string
+	MSPID        string
+	PeerName     string
+
+	// Address is the host:port of the peer with any grpc:// or grpcs:// scheme removed
+	Address       string
+	TLS           bool
+	TLSCACertPEM  []byte
+	TLSServerName string
+
+	KeepaliveTime         time.Duration
+	KeepaliveTimeout      time.Duration
+	KeepalivePermit       bool
+	MaxReceiveMessageSize int
+	MaxSendMessageSize    int
+}
+
+// Load reads a connection profile from a YAML or JSON file
+func Load(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read connection profile: %w", err)
+	}
+
+	var profile Profile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse connection profile %s: %w", path, err)
+	}
+	profile.dir = filepath.Dir(path)
+
+	return &profile, nil
+}
+
+// Endpoint resolves a peer of the organization. An empty organization selects the profile's client
+// organization and an empty peer name selects the first peer listed for the organization.
+func (p *Profile) Endpoint(organization, peerName string) (*Endpoint, error) {
+	if organization == "" {
+		organization = p.Client.Organization
+	}
+	if organization == "" {
+		if len(p.Organizations) != 1 {
+			return nil, fmt.Errorf("connection profile has %d organizations and no client organization, one must be chosen", len(p.Organizations))
+		}
+		for name := range p.Organizations {
+			organization = name
+		}
+	}
+
+	org, ok := p.Organizations[organization]
+	if !ok {
+		return nil, fmt.Errorf("organization %s not found in connection profile (available: %s)", organization, strings.Join(keys(p.Organizations), ", "))
+	}
+
+	if peerName == "" {
+		if len(org.Peers) == 0 {
+			return nil, fmt.Errorf("organization %s has no peers in connection profile", organization)
+		}
+		peerName = org.Peers[0]
+	}
+
+	peer, ok := p.Peers[peerName]
+	if !ok {
+		return nil, fmt.Errorf("peer %s not found in connection profile (available: %s)", peerName, strings.Join(keys(p.Peers), ", "))
+	}
+
+	endpoint := &Endpoint{
+		Organization: organization,
+		MSPID:        org.MSPID,
+		PeerName:     peerName,
+	}
+
+	switch {
+	case strings.HasPrefix(peer.URL, "grpcs://"):
+		endpoint.Address = strings.TrimPrefix(peer.URL, "grpcs://")
+		endpoint.TLS = true
+	case strings.HasPrefix(peer.URL, "grpc://"):
+		endpoint.Address = strings.TrimPrefix(peer.URL, "grpc://")
+	case peer.URL == "":
+		return nil, fmt.Errorf("peer %s has no url", peerName)
+	default:
+		endpoint.Address = peer.URL
+		endpoint.TLS = true
+	}
+
+	if endpoint.TLS {
+		pem, err := p.tlsCACert(peer.TLSCACerts)
+		if err != nil {
+			return nil, fmt.Errorf("peer %s: %w", peerName, err)
+		}
+		endpoint.TLSCACertPEM = pem
+	}
+
+	if err := applyGRPCOptions(endpoint, peer.GRPCOptions); err != nil {
+		return nil, fmt.Errorf("peer %s: %w", peerName, err)
+	}
+
+	return endpoint, nil
+}
+
+func (p *Profile) tlsCACert(certs TLSCACerts) ([]byte, error) {
+	if certs.PEM != "" {
+		return []byte(certs.PEM), nil
+	}
+	if certs.Path == "" {
+		return nil, fmt.Errorf("tlsCACerts requires pem or path")
+	}
+
+	path := certs.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS CA certificate: %w", err)
+	}
+
+	return pem, nil
+}
+
+// applyGRPCOptions maps the grpcOptions understood by the Fabric SDKs onto the endpoint
+func applyGRPCOptions(endpoint *Endpoint, options map[string]any) error {
+	for name, value := range options {
+		var err error
+
+		switch name {
+		case "ssl-target-name-override", "hostnameOverride":
+			endpoint.TLSServerName = fmt.Sprint(value)
+		case "grpc.keepalive_time_ms":
+			endpoint.KeepaliveTime, err = milliseconds(name, value)
+		case "grpc.keepalive_timeout_ms":
+			endpoint.KeepaliveTimeout, err = milliseconds(name, value)
+		case "grpc.keepalive_permit_without_calls", "grpc.http2.keepalive_permit_without_calls":
+			endpoint.KeepalivePermit, err = boolean(name, value)
+		case "grpc.max_receive_message_length", "grpc-max-receive-message-length":
+			endpoint.MaxReceiveMessageSize, err = integer(name, value)
+		case "grpc.max_send_message_length", "grpc-max-send-message-length":
+			endpoint.MaxSendMessageSize, err = integer(name, value)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func integer(name string, value any) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	}
+	return 0, fmt.Errorf("grpcOptions %s must be a number, got %v", name, value)
+}
+
+func milliseconds(name string, value any) (time.Duration, error) {
+	ms, err := integer(name, value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func boolean(name string, value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case int:
+		return v != 0, nil
+	}
+	return false, fmt.Errorf("grpcOptions %s must be a boolean, got %v", name, value)
+}
+
+func keys[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
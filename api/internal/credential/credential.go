@@ -0,0 +1,283 @@
+// Package credential issues training records as signed credentials that a third party can verify
+// offline. The claims are signed by the exporting Fabric identity, whose certificate chain travels
+// with the credential so it can be checked against the issuing organisation's root certificate.
+//
+// Certificates are checked at verification time, not at the credential's issuedAt: the signer
+// chooses issuedAt, so a key whose certificate has expired or been replaced could otherwise
+// backdate new credentials. A credential therefore stops verifying once any certificate in its
+// chain expires and must be exported again. issuedAt itself must fall within the signer's validity
+// and not be in the future.
+package credential
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+)
+
+// Type identifies the claims as a training credential
+const Type = "StarfleetTrainingCredential"
+
+// Signature algorithms, matching how Fabric identities sign
+const (
+	AlgorithmECDSASHA256 = "ECDSA-SHA256"
+	AlgorithmEd25519     = "Ed25519"
+)
+
+var ErrInvalid = errors.New("invalid credential")
+
+// clockSkew is how far in the future issuedAt may be, allowing for the exporter's clock running ahead
+const clockSkew = 5 * time.Minute
+
+// Claims is what the credential asserts, the training record as committed in TransactionID
+type Claims struct {
+	Type          string           `json:"type"`
+	Training      *domain.Training `json:"training"`
+	TransactionID string           `json:"transactionID"`
+	BlockNumber   uint64           `json:"blockNumber"`
+	ChannelName   string           `json:"channelName"`
+	ChaincodeName string           `json:"chaincodeName"`
+	IssuerMSPID   string           `json:"issuerMSPID"`
+	IssuedAt      string           `json:"issuedAt"`
+}
+
+// Credential is the exported document. Claims are signed exactly as held here, in compact JSON
+// form, so reformatting the file does not break the signature but editing a claim does.
+type Credential struct {
+	Claims json.RawMessage `json:"claims"`
+	// CertificateChain holds PEM certificates, the signer's first, then any intermediates and the root
+	CertificateChain   []string `json:"certificateChain"`
+	SignatureAlgorithm string   `json:"signatureAlgorithm"`
+	Signature          []byte   `json:"signature"`
+	// Proof is unsigned, it stands on the hashes and endorsements it holds
+	Proof *Proof `json:"proof,omitempty"`
+}
+
+// Issue signs claims with the signer's key. chain is the signer's certificate followed by the
+// certificates of its issuers, sign is a Fabric identity.Sign.
+func Issue(claims Claims, chain []*x509.Certificate, sign func([]byte) ([]byte, error)) (*Credential, error) {
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("the signer's certificate is required")
+	}
+
+	claims.Type = Type
+
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal claims: %w", err)
+	}
+
+	algorithm, signed, err := signedData(chain[0], claimsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := sign(signed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign claims: %w", err)
+	}
+
+	credential := &Credential{
+		Claims:             claimsJSON,
+		SignatureAlgorithm: algorithm,
+		Signature:          signature,
+	}
+	for _, certificate := range chain {
+		credential.CertificateChain = append(credential.CertificateChain, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})))
+	}
+
+	return credential, nil
+}
+
+// Result is a verified credential
+type Result struct {
+	Claims *Claims
+	Signer *x509.Certificate
+	// Anchored is true when the chain was verified up to one of the trusted roots
+	Anchored bool
+}
+
+// Verify checks the credential's structure, its signature against the signer's certificate, and
+// that each certificate in the chain signed the one before it and is valid now. With roots the chain
+// must also lead to one of them, without it the signer is unproven and Anchored is false. The ledger
+// proof, if there is one, is checked separately by VerifyProof.
+func Verify(credential *Credential, roots *x509.CertPool) (*Result, error) {
+	if len(credential.Claims) == 0 || len(credential.Signature) == 0 || len(credential.CertificateChain) == 0 {
+		return nil, fmt.Errorf("%w: claims, signature and certificate chain are required", ErrInvalid)
+	}
+
+	var claims *Claims
+	decoder := json.NewDecoder(bytes.NewReader(credential.Claims))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse claims: %v", ErrInvalid, err)
+	}
+	if err := checkClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	issuedAt, _ := time.Parse(time.RFC3339, claims.IssuedAt)
+
+	chain, err := parseChain(credential.CertificateChain)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	signer := chain[0]
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, credential.Claims); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse claims: %v", ErrInvalid, err)
+	}
+
+	algorithm, signed, err := signedData(signer, compact.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if algorithm != credential.SignatureAlgorithm {
+		return nil, fmt.Errorf("%w: signature algorithm %s does not match the signer's %s key", ErrInvalid, credential.SignatureAlgorithm, algorithm)
+	}
+	if !verifySignature(signer, signed, credential.Signature) {
+		return nil, fmt.Errorf("%w: signature does not match the claims and signer certificate", ErrInvalid)
+	}
+
+	now := time.Now()
+	if issuedAt.After(now.Add(clockSkew)) {
+		return nil, fmt.Errorf("%w: issuedAt %s is in the future", ErrInvalid, claims.IssuedAt)
+	}
+	if issuedAt.Before(signer.NotBefore) || issuedAt.After(signer.NotAfter) {
+		return nil, fmt.Errorf("%w: issuedAt %s is outside the signer certificate's validity", ErrInvalid, claims.IssuedAt)
+	}
+
+	for i, certificate := range chain {
+		if now.Before(certificate.NotBefore) || now.After(certificate.NotAfter) {
+			return nil, fmt.Errorf("%w: certificate %q is not valid now (valid %s to %s)", ErrInvalid, certificate.Subject, certificate.NotBefore.Format(time.RFC3339), certificate.NotAfter.Format(time.RFC3339))
+		}
+		if i+1 < len(chain) {
+			if err := certificate.CheckSignatureFrom(chain[i+1]); err != nil {
+				return nil, fmt.Errorf("%w: certificate %q was not issued by %q: %v", ErrInvalid, certificate.Subject, chain[i+1].Subject, err)
+			}
+		}
+	}
+
+	result := &Result{
+		Claims: claims,
+		Signer: signer,
+	}
+
+	if roots != nil {
+		intermediates := x509.NewCertPool()
+		for _, certificate := range chain[1:] {
+			intermediates.AddCert(certificate)
+		}
+
+		_, err := signer.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   now,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: signer is not trusted: %v", ErrInvalid, err)
+		}
+		result.Anchored = true
+	}
+
+	return result, nil
+}
+
+func checkClaims(claims *Claims) error {
+	if claims == nil {
+		return fmt.Errorf("claims are empty")
+	}
+	if claims.Type != Type {
+		return fmt.Errorf("claims type %q is not %s", claims.Type, Type)
+	}
+	if claims.Training == nil || claims.Training.RecordID == "" || claims.Training.PersonnelID == "" || claims.Training.TrainingCode == "" {
+		return fmt.Errorf("claims must hold a training record with recordID, personnelID and trainingCode")
+	}
+	if claims.TransactionID == "" {
+		return fmt.Errorf("transactionID is required")
+	}
+	if claims.IssuerMSPID == "" {
+		return fmt.Errorf("issuerMSPID is required")
+	}
+	if _, err := time.Parse(time.RFC3339, claims.IssuedAt); err != nil {
+		return fmt.Errorf("issuedAt must be RFC3339: %v", err)
+	}
+	return nil
+}
+
+func parseChain(chainPEM []string) ([]*x509.Certificate, error) {
+	chain := make([]*x509.Certificate, len(chainPEM))
+	for i, certificatePEM := range chainPEM {
+		block, rest := pem.Decode([]byte(certificatePEM))
+		if block == nil || block.Type != "CERTIFICATE" || len(bytes.TrimSpace(rest)) > 0 {
+			return nil, fmt.Errorf("certificate %d must be a single PEM certificate", i)
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate %d: %v", i, err)
+		}
+		chain[i] = certificate
+	}
+	return chain, nil
+}
+
+// signedData returns what a Fabric sign function is given for the certificate's key type
+func signedData(certificate *x509.Certificate, message []byte) (string, []byte, error) {
+	switch certificate.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return AlgorithmECDSASHA256, digest[:], nil
+	case ed25519.PublicKey:
+		return AlgorithmEd25519, message, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported signer key type %T", certificate.PublicKey)
+	}
+}
+
+func verifySignature(certificate *x509.Certificate, signed, signature []byte) bool {
+	switch key := certificate.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, signed, signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, signed, signature)
+	default:
+		return false
+	}
+}
+
+// ReadCertificates parses every PEM certificate in data, for chain and root files
+func ReadCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no PEM certificates found")
+	}
+	return certificates, nil
+}
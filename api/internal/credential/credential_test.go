@@ -0,0 +1,200 @@
+package credential
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+)
+
+// testCertificate is a generated key pair, signed by parent or self-signed when parent is nil
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+func newTestCertificate(t *testing.T, commonName string, parent *testCertificate, notBefore, notAfter time.Time) *testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("failed to generate serial: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+
+	signer, signerCertificate := key, template
+	if parent != nil {
+		signer, signerCertificate = parent.key, parent.certificate
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCertificate, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	return &testCertificate{certificate: certificate, key: key}
+}
+
+// sign is a Fabric identity.Sign for an ECDSA key, which is given the digest to sign
+func (c *testCertificate) sign(digest []byte) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, c.key, digest)
+}
+
+func (c *testCertificate) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(c.certificate)
+	return pool
+}
+
+func testClaims(issuedAt time.Time) Claims {
+	return Claims{
+		Training: &domain.Training{
+			RecordID:     "TR-001",
+			PersonnelID:  "SF-001",
+			Campus:       "Engineering",
+			TrainingCode: "ENG-WARP-201",
+			CompletedAt:  "2024-06-01T12:00:00Z",
+			IssuedBy:     "Captain Janeway",
+			Status:       domain.TrainingStatusCompleted,
+		},
+		TransactionID: "tx1",
+		BlockNumber:   5,
+		ChannelName:   "academy",
+		ChaincodeName: "starfleet-personnel",
+		IssuerMSPID:   "AcademyMSP",
+		IssuedAt:      issuedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func issue(t *testing.T, claims Claims, chain ...*testCertificate) *Credential {
+	t.Helper()
+
+	certificates := make([]*x509.Certificate, len(chain))
+	for i, certificate := range chain {
+		certificates[i] = certificate.certificate
+	}
+
+	credential, err := Issue(claims, certificates, chain[0].sign)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	return credential
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	ca := newTestCertificate(t, "ca.academy", nil, now.Add(-time.Hour), now.Add(time.Hour))
+	signer := newTestCertificate(t, "registrar.academy", ca, now.Add(-time.Hour), now.Add(time.Hour))
+
+	t.Run("intact credential is anchored to the root", func(t *testing.T) {
+		result, err := Verify(issue(t, testClaims(now), signer, ca), ca.pool())
+		if err != nil {
+			t.Fatalf("expected the credential to verify, got %v", err)
+		}
+		if !result.Anchored {
+			t.Fatal("expected the credential to be anchored")
+		}
+		if result.Claims.Training.RecordID != "TR-001" {
+			t.Fatalf("expected the claims for TR-001, got %s", result.Claims.Training.RecordID)
+		}
+	})
+
+	t.Run("credential without roots is not anchored", func(t *testing.T) {
+		result, err := Verify(issue(t, testClaims(now), signer, ca), nil)
+		if err != nil {
+			t.Fatalf("expected the credential to verify, got %v", err)
+		}
+		if result.Anchored {
+			t.Fatal("expected the credential not to be anchored")
+		}
+	})
+
+	t.Run("credential under another root is rejected", func(t *testing.T) {
+		other := newTestCertificate(t, "ca.elsewhere", nil, now.Add(-time.Hour), now.Add(time.Hour))
+
+		_, err := Verify(issue(t, testClaims(now), signer, ca), other.pool())
+		expectInvalid(t, err, "signer is not trusted")
+	})
+
+	t.Run("tampered claim is rejected", func(t *testing.T) {
+		credential := issue(t, testClaims(now), signer, ca)
+		credential.Claims = json.RawMessage(strings.Replace(string(credential.Claims), "SF-001", "SF-002", 1))
+
+		_, err := Verify(credential, ca.pool())
+		expectInvalid(t, err, "signature does not match")
+	})
+
+	t.Run("mismatched signature algorithm is rejected", func(t *testing.T) {
+		credential := issue(t, testClaims(now), signer, ca)
+		credential.SignatureAlgorithm = AlgorithmEd25519
+
+		_, err := Verify(credential, ca.pool())
+		expectInvalid(t, err, "signature algorithm")
+	})
+
+	t.Run("issuedAt before the signer's validity is rejected", func(t *testing.T) {
+		_, err := Verify(issue(t, testClaims(now.Add(-2*time.Hour)), signer, ca), ca.pool())
+		expectInvalid(t, err, "outside the signer certificate's validity")
+	})
+
+	t.Run("issuedAt in the future is rejected", func(t *testing.T) {
+		_, err := Verify(issue(t, testClaims(now.Add(30*time.Minute)), signer, ca), ca.pool())
+		expectInvalid(t, err, "in the future")
+	})
+
+	t.Run("expired certificate is rejected even for a credential issued while it was valid", func(t *testing.T) {
+		expired := newTestCertificate(t, "retired.academy", ca, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+
+		_, err := Verify(issue(t, testClaims(now.Add(-30*time.Hour)), expired, ca), ca.pool())
+		expectInvalid(t, err, "not valid now")
+	})
+
+	t.Run("broken chain is rejected", func(t *testing.T) {
+		other := newTestCertificate(t, "ca.elsewhere", nil, now.Add(-time.Hour), now.Add(time.Hour))
+
+		_, err := Verify(issue(t, testClaims(now), signer, other), nil)
+		expectInvalid(t, err, "was not issued by")
+	})
+}
+
+func expectInvalid(t *testing.T, err error, reason string) {
+	t.Helper()
+
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+	if !strings.Contains(err.Error(), reason) {
+		t.Fatalf("expected the error to mention %q, got %v", reason, err)
+	}
+}
@@ -0,0 +1,382 @@
+package credential
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/blockreplay"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// Proof shows the credential's transaction is in a block and was endorsed. Fabric hashes a block's
+// envelopes together rather than as a Merkle tree, so every envelope of the block is needed to
+// recompute the header's data hash, not only the credential's own transaction. The endorsements
+// are read from that envelope rather than kept alongside it, so the two can never disagree.
+type Proof struct {
+	BlockHeader BlockHeader `json:"blockHeader"`
+	// BlockHash is the SHA-256 of the header, the previous hash recorded by the next block
+	BlockHash        []byte   `json:"blockHash"`
+	BlockData        [][]byte `json:"blockData"`
+	TransactionIndex int      `json:"transactionIndex"`
+	// ValidationCode is the committing peer's verdict from the block metadata. The block hash does
+	// not cover it, so it is reported but never trusted.
+	ValidationCode string `json:"validationCode"`
+}
+
+type BlockHeader struct {
+	Number       uint64 `json:"number"`
+	PreviousHash []byte `json:"previousHash"`
+	DataHash     []byte `json:"dataHash"`
+}
+
+// NewProof packages the block holding transactionID, as returned by qscc GetBlockByTxID
+func NewProof(block *common.Block, transactionID string) (*Proof, error) {
+	header := block.GetHeader()
+	data := block.GetData().GetData()
+
+	index := -1
+	for i, envelopeBytes := range data {
+		channelHeader, err := readChannelHeader(envelopeBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transaction %d of block %d: %w", i, header.GetNumber(), err)
+		}
+		if channelHeader.GetTxId() == transactionID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("transaction %s is not in block %d", transactionID, header.GetNumber())
+	}
+
+	validationCode := peer.TxValidationCode_NOT_VALIDATED
+	if metadata := block.GetMetadata().GetMetadata(); len(metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		if codes := metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]; index < len(codes) {
+			validationCode = peer.TxValidationCode(codes[index])
+		}
+	}
+
+	proof := &Proof{
+		BlockHeader: BlockHeader{
+			Number:       header.GetNumber(),
+			PreviousHash: header.GetPreviousHash(),
+			DataHash:     header.GetDataHash(),
+		},
+		BlockData:        data,
+		TransactionIndex: index,
+		ValidationCode:   validationCode.String(),
+	}
+
+	hash, err := proof.BlockHeader.hash()
+	if err != nil {
+		return nil, err
+	}
+	proof.BlockHash = hash
+
+	return proof, nil
+}
+
+// hash is Fabric's block header hash, SHA-256 over the ASN.1 encoding of the three fields
+func (h BlockHeader) hash() ([]byte, error) {
+	encoded, err := asn1.Marshal(struct {
+		Number       *big.Int
+		PreviousHash []byte
+		DataHash     []byte
+	}{new(big.Int).SetUint64(h.Number), h.PreviousHash, h.DataHash})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode block header: %w", err)
+	}
+
+	hash := sha256.Sum256(encoded)
+	return hash[:], nil
+}
+
+// Endorsement is a verified endorser signature over the transaction's results
+type Endorsement struct {
+	MSPID       string
+	Certificate *x509.Certificate
+	// Anchored is true when the endorser's certificate was verified up to one of its MSP's roots
+	Anchored bool
+}
+
+// ProofOptions is what the verifier trusts when checking a proof
+type ProofOptions struct {
+	// EndorserRoots holds each endorsing organisation's root certificates by MSP ID. An endorsement
+	// only counts for an MSP when its certificate leads to that MSP's own roots.
+	EndorserRoots map[string]*x509.CertPool
+	// Endorsers are the MSP IDs that must have endorsed, each needs an entry in EndorserRoots
+	Endorsers []string
+	// BlockHash, when set, is the hash of the credential's block as obtained from a trusted peer,
+	// such as the previous hash of the following block
+	BlockHash []byte
+}
+
+// ProofResult is a verified proof
+type ProofResult struct {
+	BlockNumber  uint64
+	BlockHash    []byte
+	PreviousHash []byte
+	// OnChain is true when the block hash matched ProofOptions.BlockHash, otherwise the block is only
+	// shown to be self-consistent
+	OnChain bool
+	// Function is the contract function the transaction invoked
+	Function     string
+	Endorsements []Endorsement
+	// ValidationCode is copied unverified from the proof, see Proof.ValidationCode
+	ValidationCode string
+}
+
+// VerifyProof checks the proof holds the transaction the claims were signed for, that the block
+// header covers it, that it wrote the claimed training record, and that every endorsement signature
+// matches. Each of the required endorsers must have endorsed with a certificate issued under its own
+// roots, and when a trusted block hash is given the block must match it.
+//
+// The validation code is outside the block hash, so a proof reporting anything other than VALID is
+// rejected but one reporting VALID proves nothing; without a trusted block hash, whether the block
+// is on the channel is left to the verifier. Certificates are checked at verification time, for the
+// reasons given in the package documentation.
+func VerifyProof(claims *Claims, proof *Proof, options ProofOptions) (*ProofResult, error) {
+	for _, mspID := range options.Endorsers {
+		if options.EndorserRoots[mspID] == nil {
+			return nil, fmt.Errorf("no root certificates given for endorser %s", mspID)
+		}
+	}
+
+	if proof.BlockHeader.Number != claims.BlockNumber {
+		return nil, fmt.Errorf("%w: proof is for block %d, the claims for block %d", ErrInvalid, proof.BlockHeader.Number, claims.BlockNumber)
+	}
+
+	dataHash := sha256.Sum256(bytes.Join(proof.BlockData, nil))
+	if !bytes.Equal(dataHash[:], proof.BlockHeader.DataHash) {
+		return nil, fmt.Errorf("%w: block data does not match the block header's data hash", ErrInvalid)
+	}
+
+	blockHash, err := proof.BlockHeader.hash()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if !bytes.Equal(blockHash, proof.BlockHash) {
+		return nil, fmt.Errorf("%w: block hash does not match the block header", ErrInvalid)
+	}
+	if options.BlockHash != nil && !bytes.Equal(blockHash, options.BlockHash) {
+		return nil, fmt.Errorf("%w: block %d hash %x is not the trusted hash %x", ErrInvalid, proof.BlockHeader.Number, blockHash, options.BlockHash)
+	}
+
+	if proof.ValidationCode != peer.TxValidationCode_VALID.String() {
+		return nil, fmt.Errorf("%w: proof reports the transaction was committed as %s, not VALID", ErrInvalid, proof.ValidationCode)
+	}
+	if proof.TransactionIndex < 0 || proof.TransactionIndex >= len(proof.BlockData) {
+		return nil, fmt.Errorf("%w: transaction index %d is outside the block", ErrInvalid, proof.TransactionIndex)
+	}
+	envelopeBytes := proof.BlockData[proof.TransactionIndex]
+
+	channelHeader, err := readChannelHeader(envelopeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if channelHeader.GetTxId() != claims.TransactionID {
+		return nil, fmt.Errorf("%w: proof holds transaction %s, not %s", ErrInvalid, channelHeader.GetTxId(), claims.TransactionID)
+	}
+	if channelHeader.GetChannelId() != claims.ChannelName {
+		return nil, fmt.Errorf("%w: transaction is on channel %s, not %s", ErrInvalid, channelHeader.GetChannelId(), claims.ChannelName)
+	}
+
+	if err := checkWrite(envelopeBytes, claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	result := &ProofResult{
+		BlockNumber:    proof.BlockHeader.Number,
+		BlockHash:      proof.BlockHash,
+		PreviousHash:   proof.BlockHeader.PreviousHash,
+		OnChain:        options.BlockHash != nil,
+		ValidationCode: proof.ValidationCode,
+	}
+
+	actions, err := readActions(envelopeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	for _, action := range actions {
+		if result.Function == "" {
+			result.Function = action.function
+		}
+
+		for _, endorsement := range action.endorsements {
+			verified, err := verifyEndorsement(action.responsePayload, endorsement, options.EndorserRoots)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+			}
+			result.Endorsements = append(result.Endorsements, *verified)
+		}
+	}
+
+	for _, mspID := range options.Endorsers {
+		endorsed := slices.ContainsFunc(result.Endorsements, func(e Endorsement) bool {
+			return e.MSPID == mspID && e.Anchored
+		})
+		if !endorsed {
+			return nil, fmt.Errorf("%w: transaction was not endorsed by %s", ErrInvalid, mspID)
+		}
+	}
+
+	return result, nil
+}
+
+// checkWrite confirms the transaction wrote the claimed training record to the chaincode's namespace
+func checkWrite(envelopeBytes []byte, claims *Claims) error {
+	transaction, err := blockreplay.DecodeEnvelope(envelopeBytes, claims.ChaincodeName)
+	if err != nil {
+		return err
+	}
+	if transaction == nil {
+		return fmt.Errorf("transaction %s is not an endorser transaction", claims.TransactionID)
+	}
+
+	for _, write := range transaction.Writes {
+		if write.DocType != blockreplay.DocTypeTraining || write.ID != claims.Training.RecordID || write.IsDelete {
+			continue
+		}
+
+		var written domain.Training
+		if err := json.Unmarshal(write.Value, &written); err != nil {
+			return fmt.Errorf("failed to unmarshal the written training record: %v", err)
+		}
+		if written != *claims.Training {
+			return fmt.Errorf("training record %s written by the transaction differs from the claims", claims.Training.RecordID)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("transaction %s did not write training record %s in %s", claims.TransactionID, claims.Training.RecordID, claims.ChaincodeName)
+}
+
+type endorsedAction struct {
+	function        string
+	responsePayload []byte
+	endorsements    []*peer.Endorsement
+}
+
+func readEnvelopePayload(envelopeBytes []byte) (*common.Payload, error) {
+	envelope := &common.Envelope{}
+	if err := proto.Unmarshal(envelopeBytes, envelope); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal envelope: %w", err)
+	}
+
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.GetPayload(), payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	return payload, nil
+}
+
+func readChannelHeader(envelopeBytes []byte) (*common.ChannelHeader, error) {
+	payload, err := readEnvelopePayload(envelopeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), channelHeader); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal channel header: %w", err)
+	}
+
+	return channelHeader, nil
+}
+
+func readActions(envelopeBytes []byte) ([]endorsedAction, error) {
+	payload, err := readEnvelopePayload(envelopeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := &peer.Transaction{}
+	if err := proto.Unmarshal(payload.GetData(), transaction); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+	}
+
+	var actions []endorsedAction
+	for _, action := range transaction.GetActions() {
+		actionPayload := &peer.ChaincodeActionPayload{}
+		if err := proto.Unmarshal(action.GetPayload(), actionPayload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal chaincode action payload: %w", err)
+		}
+
+		proposalPayload := &peer.ChaincodeProposalPayload{}
+		if err := proto.Unmarshal(actionPayload.GetChaincodeProposalPayload(), proposalPayload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal chaincode proposal payload: %w", err)
+		}
+
+		invocation := &peer.ChaincodeInvocationSpec{}
+		if err := proto.Unmarshal(proposalPayload.GetInput(), invocation); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal chaincode invocation: %w", err)
+		}
+
+		var function string
+		if args := invocation.GetChaincodeSpec().GetInput().GetArgs(); len(args) > 0 {
+			function = string(args[0])
+		}
+
+		actions = append(actions, endorsedAction{
+			function:        function,
+			responsePayload: actionPayload.GetAction().GetProposalResponsePayload(),
+			endorsements:    actionPayload.GetAction().GetEndorsements(),
+		})
+	}
+
+	return actions, nil
+}
+
+// verifyEndorsement checks the endorser's signature, which covers the proposal response payload
+// followed by the endorser's serialized identity, and anchors the certificate to the roots of the
+// MSP it claims to be from. The MSP ID is only a label, so it is never matched against other roots.
+func verifyEndorsement(responsePayload []byte, endorsement *peer.Endorsement, endorserRoots map[string]*x509.CertPool) (*Endorsement, error) {
+	identity := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(endorsement.GetEndorser(), identity); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal endorser identity: %v", err)
+	}
+
+	block, _ := pem.Decode(identity.GetIdBytes())
+	if block == nil {
+		return nil, fmt.Errorf("endorser from %s has no PEM certificate", identity.GetMspid())
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse endorser certificate from %s: %v", identity.GetMspid(), err)
+	}
+
+	_, signed, err := signedData(certificate, slices.Concat(responsePayload, endorsement.GetEndorser()))
+	if err != nil {
+		return nil, err
+	}
+	if !verifySignature(certificate, signed, endorsement.GetSignature()) {
+		return nil, fmt.Errorf("endorsement signature from %q (%s) does not match", certificate.Subject, identity.GetMspid())
+	}
+
+	verified := &Endorsement{
+		MSPID:       identity.GetMspid(),
+		Certificate: certificate,
+	}
+
+	if roots := endorserRoots[identity.GetMspid()]; roots != nil {
+		_, err := certificate.Verify(x509.VerifyOptions{
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		verified.Anchored = err == nil
+	}
+
+	return verified, nil
+}
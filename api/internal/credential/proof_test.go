@@ -0,0 +1,233 @@
+package credential
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+func marshal(t *testing.T, message proto.Message) []byte {
+	t.Helper()
+
+	data, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatalf("failed to marshal %T: %v", message, err)
+	}
+	return data
+}
+
+// testEnvelope is an endorser transaction writing training, endorsed by endorser as mspID
+func testEnvelope(t *testing.T, txID, chaincodeName string, training *domain.Training, endorser *testCertificate, mspID string) []byte {
+	t.Helper()
+
+	// Stored as the chaincode repository writes it, with the schema version alongside the fields
+	value, err := json.Marshal(struct {
+		*domain.Training
+		SchemaVersion int `json:"schemaVersion"`
+	}{training, 1})
+	if err != nil {
+		t.Fatalf("failed to marshal training: %v", err)
+	}
+
+	kvSet := marshal(t, &kvrwset.KVRWSet{
+		Writes: []*kvrwset.KVWrite{{Key: "training:" + training.RecordID, Value: value}},
+	})
+	results := marshal(t, &rwset.TxReadWriteSet{
+		NsRwset: []*rwset.NsReadWriteSet{{Namespace: chaincodeName, Rwset: kvSet}},
+	})
+	responsePayload := marshal(t, &peer.ProposalResponsePayload{
+		Extension: marshal(t, &peer.ChaincodeAction{Results: results}),
+	})
+
+	identity := marshal(t, &msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: endorser.certificate.Raw}),
+	})
+	digest := sha256.Sum256(slices.Concat(responsePayload, identity))
+	signature, err := endorser.sign(digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign endorsement: %v", err)
+	}
+
+	input := marshal(t, &peer.ChaincodeInvocationSpec{
+		ChaincodeSpec: &peer.ChaincodeSpec{
+			Input: &peer.ChaincodeInput{Args: [][]byte{[]byte("TrainingContract:CompleteTraining")}},
+		},
+	})
+	actionPayload := marshal(t, &peer.ChaincodeActionPayload{
+		ChaincodeProposalPayload: marshal(t, &peer.ChaincodeProposalPayload{Input: input}),
+		Action: &peer.ChaincodeEndorsedAction{
+			ProposalResponsePayload: responsePayload,
+			Endorsements:            []*peer.Endorsement{{Endorser: identity, Signature: signature}},
+		},
+	})
+
+	channelHeader := marshal(t, &common.ChannelHeader{
+		Type:      int32(common.HeaderType_ENDORSER_TRANSACTION),
+		TxId:      txID,
+		ChannelId: "academy",
+	})
+	payload := marshal(t, &common.Payload{
+		Header: &common.Header{ChannelHeader: channelHeader},
+		Data:   marshal(t, &peer.Transaction{Actions: []*peer.TransactionAction{{Payload: actionPayload}}}),
+	})
+
+	return marshal(t, &common.Envelope{Payload: payload})
+}
+
+// testProof is the proof for tx1, the second transaction of block 5, writing training
+func testProof(t *testing.T, training *domain.Training, endorser *testCertificate, mspID string) *Proof {
+	t.Helper()
+
+	other := *training
+	other.RecordID = "TR-000"
+
+	data := [][]byte{
+		testEnvelope(t, "tx0", "starfleet-personnel", &other, endorser, mspID),
+		testEnvelope(t, "tx1", "starfleet-personnel", training, endorser, mspID),
+	}
+	dataHash := sha256.Sum256(slices.Concat(data...))
+
+	block := &common.Block{
+		Header: &common.BlockHeader{Number: 5, PreviousHash: []byte("previous"), DataHash: dataHash[:]},
+		Data:   &common.BlockData{Data: data},
+		Metadata: &common.BlockMetadata{
+			Metadata: [][]byte{common.BlockMetadataIndex_TRANSACTIONS_FILTER: {byte(peer.TxValidationCode_VALID), byte(peer.TxValidationCode_VALID)}},
+		},
+	}
+
+	proof, err := NewProof(block, "tx1")
+	if err != nil {
+		t.Fatalf("NewProof: %v", err)
+	}
+	return proof
+}
+
+func TestVerifyProof(t *testing.T) {
+	now := time.Now()
+	academyCA := newTestCertificate(t, "ca.academy", nil, now.Add(-time.Hour), now.Add(time.Hour))
+	fleetCA := newTestCertificate(t, "ca.fleet", nil, now.Add(-time.Hour), now.Add(time.Hour))
+	academyPeer := newTestCertificate(t, "peer0.academy", academyCA, now.Add(-time.Hour), now.Add(time.Hour))
+
+	endorserRoots := map[string]*x509.CertPool{
+		"AcademyMSP": academyCA.pool(),
+		"FleetMSP":   fleetCA.pool(),
+	}
+	required := ProofOptions{EndorserRoots: endorserRoots, Endorsers: []string{"AcademyMSP"}}
+
+	newClaims := func() *Claims {
+		claims := testClaims(now)
+		return &claims
+	}
+
+	t.Run("intact proof verifies", func(t *testing.T) {
+		claims := newClaims()
+		proof := testProof(t, claims.Training, academyPeer, "AcademyMSP")
+
+		result, err := VerifyProof(claims, proof, required)
+		if err != nil {
+			t.Fatalf("expected the proof to verify, got %v", err)
+		}
+		if result.Function != "TrainingContract:CompleteTraining" {
+			t.Fatalf("expected the CompleteTraining function, got %s", result.Function)
+		}
+		if len(result.Endorsements) != 1 || !result.Endorsements[0].Anchored {
+			t.Fatalf("expected one anchored endorsement, got %+v", result.Endorsements)
+		}
+		if result.OnChain {
+			t.Fatal("expected the block not to be confirmed without a trusted block hash")
+		}
+		if result.ValidationCode != "VALID" {
+			t.Fatalf("expected the reported validation code, got %s", result.ValidationCode)
+		}
+	})
+
+	t.Run("matching trusted block hash confirms the block", func(t *testing.T) {
+		claims := newClaims()
+		proof := testProof(t, claims.Training, academyPeer, "AcademyMSP")
+
+		result, err := VerifyProof(claims, proof, ProofOptions{BlockHash: proof.BlockHash})
+		if err != nil {
+			t.Fatalf("expected the proof to verify, got %v", err)
+		}
+		if !result.OnChain {
+			t.Fatal("expected the block to be confirmed")
+		}
+	})
+
+	t.Run("altered block data is rejected", func(t *testing.T) {
+		claims := newClaims()
+		proof := testProof(t, claims.Training, academyPeer, "AcademyMSP")
+		proof.BlockData[0] = append(slices.Clone(proof.BlockData[0]), 0)
+
+		_, err := VerifyProof(claims, proof, required)
+		expectInvalid(t, err, "data hash")
+	})
+
+	t.Run("wrong transaction index is rejected", func(t *testing.T) {
+		claims := newClaims()
+		proof := testProof(t, claims.Training, academyPeer, "AcademyMSP")
+		proof.TransactionIndex = 0
+
+		_, err := VerifyProof(claims, proof, required)
+		expectInvalid(t, err, "proof holds transaction tx0")
+	})
+
+	t.Run("wrong transaction ID is rejected", func(t *testing.T) {
+		claims := newClaims()
+		proof := testProof(t, claims.Training, academyPeer, "AcademyMSP")
+		claims.TransactionID = "tx2"
+
+		_, err := VerifyProof(claims, proof, required)
+		expectInvalid(t, err, "not tx2")
+	})
+
+	t.Run("written record that differs from the claims is rejected", func(t *testing.T) {
+		claims := newClaims()
+		written := *claims.Training
+		written.CompletedAt = "2024-07-01T12:00:00Z"
+		proof := testProof(t, &written, academyPeer, "AcademyMSP")
+
+		_, err := VerifyProof(claims, proof, required)
+		expectInvalid(t, err, "differs from the claims")
+	})
+
+	t.Run("endorser certificate under another MSP's root is rejected", func(t *testing.T) {
+		claims := newClaims()
+		proof := testProof(t, claims.Training, academyPeer, "FleetMSP")
+
+		_, err := VerifyProof(claims, proof, ProofOptions{EndorserRoots: endorserRoots, Endorsers: []string{"FleetMSP"}})
+		expectInvalid(t, err, "not endorsed by FleetMSP")
+	})
+
+	t.Run("required endorser without roots is rejected", func(t *testing.T) {
+		claims := newClaims()
+		proof := testProof(t, claims.Training, academyPeer, "AcademyMSP")
+
+		_, err := VerifyProof(claims, proof, ProofOptions{Endorsers: []string{"AcademyMSP"}})
+		if err == nil {
+			t.Fatal("expected an endorser without roots to be rejected")
+		}
+	})
+
+	t.Run("mismatched trusted block hash is rejected", func(t *testing.T) {
+		claims := newClaims()
+		proof := testProof(t, claims.Training, academyPeer, "AcademyMSP")
+		trusted := sha256.Sum256([]byte("another block"))
+
+		_, err := VerifyProof(claims, proof, ProofOptions{EndorserRoots: endorserRoots, BlockHash: trusted[:]})
+		expectInvalid(t, err, "is not the trusted hash")
+	})
+}
@@ -0,0 +1,289 @@
+package fabricgateway
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/connprofile"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/wallet"
+)
+
+type Config struct {
+	// ConnectionProfilePath points at a common connection profile, when set the peer endpoint,
+	// TLS settings and MSP ID are taken from the profile's Organization and PeerName by ApplyEnv
+	ConnectionProfilePath string
+	Organization          string
+	PeerName              string
+
+	PeerEndpoint  string
+	TLSCertPath   string
+	TLSCertPEM    []byte
+	TLSServerName string
+	TLSDisabled   bool
+
+	KeepaliveTime         time.Duration
+	KeepaliveTimeout      time.Duration
+	KeepalivePermit       bool
+	MaxReceiveMessageSize int
+	MaxSendMessageSize    int
+
+	MSPID    string
+	CertPath string
+	CertPEM  []byte
+	KeyPath  string
+	KeyPEM   []byte
+
+	// WalletPath and Identity select a labelled identity from a wallet in place of CertPath and KeyPath
+	WalletPath string
+	Identity   string
+
+	ChannelName   string
+	ChaincodeName string
+
+	EvaluateTimeout     time.Duration
+	EndorseTimeout      time.Duration
+	SubmitTimeout       time.Duration
+	CommitStatusTimeout time.Duration
+
+	// ConnectTimeout bounds each attempt to bring the gRPC connection to a ready state
+	ConnectTimeout time.Duration
+	// ConnectAttempts is the number of dial attempts made before giving up
+	ConnectAttempts int
+	// ConnectBackoff is the delay after the first failed attempt, doubling on each retry
+	ConnectBackoff time.Duration
+}
+
+// DefaultConfig returns the settings used when nothing has been configured
+func DefaultConfig() Config {
+	return Config{
+		PeerEndpoint: "localhost:7051",
+		TLSCertPath:  "./crypto-config/peers/peer.example.com/tls/ica.tls.organisation.cert",
+
+		MSPID:    "orgMSP",
+		CertPath: "./crypto-config/users/user/msp/signcerts/cert.pem",
+		KeyPath:  "./crypto-config/users/user/msp/keystore/key_sk",
+
+		WalletPath: wallet.DefaultPath,
+
+		ChannelName:   "channel",
+		ChaincodeName: "chaincode",
+
+		EvaluateTimeout:     5 * time.Second,
+		EndorseTimeout:      15 * time.Second,
+		SubmitTimeout:       5 * time.Second,
+		CommitStatusTimeout: time.Minute,
+
+		ConnectTimeout:  5 * time.Second,
+		ConnectAttempts: 5,
+		ConnectBackoff:  500 * time.Millisecond,
+	}
+}
+
+// ConfigFromEnv returns the default config overridden by any environment variables that are set
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig()
+	if err := config.ApplyEnv(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// ApplyEnv overrides fields of the config with any environment variables that are set. The
+// connection profile, configured or chosen by CONNECTION_PROFILE, is applied first so the other
+// variables override what it sets.
+func (c *Config) ApplyEnv() error {
+	stringFromEnv("CONNECTION_PROFILE", &c.ConnectionProfilePath)
+	stringFromEnv("ORGANIZATION", &c.Organization)
+	stringFromEnv("PEER_NAME", &c.PeerName)
+
+	if err := c.ApplyConnectionProfile(); err != nil {
+		return fmt.Errorf("invalid connection profile: %w", err)
+	}
+
+	stringFromEnv("PEER_ENDPOINT", &c.PeerEndpoint)
+	if value := os.Getenv("PEER_TLS_CERT"); value != "" {
+		// A file given here replaces the certificate the connection profile embedded
+		c.TLSCertPath = value
+		c.TLSCertPEM = nil
+	}
+	// Override server name for TLS verification to match certificate
+	// This allows connecting to localhost while using peer's certificate
+	stringFromEnv("TLS_SERVER_NAME", &c.TLSServerName)
+
+	stringFromEnv("MSP_ID", &c.MSPID)
+	stringFromEnv("CERT_PATH", &c.CertPath)
+	stringFromEnv("KEY_PATH", &c.KeyPath)
+	stringFromEnv("WALLET_PATH", &c.WalletPath)
+	stringFromEnv("IDENTITY", &c.Identity)
+
+	stringFromEnv("CHANNEL_NAME", &c.ChannelName)
+	stringFromEnv("CHAINCODE_NAME", &c.ChaincodeName)
+
+	durations := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"EVALUATE_TIMEOUT", &c.EvaluateTimeout},
+		{"ENDORSE_TIMEOUT", &c.EndorseTimeout},
+		{"SUBMIT_TIMEOUT", &c.SubmitTimeout},
+		{"COMMIT_STATUS_TIMEOUT", &c.CommitStatusTimeout},
+		{"CONNECT_TIMEOUT", &c.ConnectTimeout},
+		{"CONNECT_BACKOFF", &c.ConnectBackoff},
+	}
+	for _, d := range durations {
+		if err := durationFromEnv(d.name, d.value); err != nil {
+			return err
+		}
+	}
+
+	if value := os.Getenv("CONNECT_ATTEMPTS"); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid CONNECT_ATTEMPTS %q: %w", value, err)
+		}
+		c.ConnectAttempts = attempts
+	}
+
+	return nil
+}
+
+// ApplyConnectionProfile replaces the peer endpoint, TLS settings and MSP ID with those of the
+// configured connection profile, it does nothing when no profile is configured
+func (c *Config) ApplyConnectionProfile() error {
+	if c.ConnectionProfilePath == "" {
+		return nil
+	}
+
+	profile, err := connprofile.Load(c.ConnectionProfilePath)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := profile.Endpoint(c.Organization, c.PeerName)
+	if err != nil {
+		return err
+	}
+
+	c.PeerEndpoint = endpoint.Address
+	c.TLSDisabled = !endpoint.TLS
+	c.TLSCertPath = ""
+	c.TLSCertPEM = endpoint.TLSCACertPEM
+	c.TLSServerName = endpoint.TLSServerName
+	if endpoint.MSPID != "" {
+		c.MSPID = endpoint.MSPID
+	}
+
+	c.KeepaliveTime = endpoint.KeepaliveTime
+	c.KeepaliveTimeout = endpoint.KeepaliveTimeout
+	c.KeepalivePermit = endpoint.KeepalivePermit
+	c.MaxReceiveMessageSize = endpoint.MaxReceiveMessageSize
+	c.MaxSendMessageSize = endpoint.MaxSendMessageSize
+
+	return nil
+}
+
+// ApplyWallet replaces the MSP ID, certificate and private key with the selected wallet identity,
+// it does nothing when no identity is selected
+func (c *Config) ApplyWallet() error {
+	if c.Identity == "" {
+		return nil
+	}
+
+	w, err := wallet.Open(c.WalletPath)
+	if err != nil {
+		return err
+	}
+
+	id, err := w.Get(c.Identity)
+	if err != nil {
+		return err
+	}
+
+	c.MSPID = id.MSPID
+	c.CertPath = ""
+	c.CertPEM = []byte(id.Credentials.Certificate)
+	c.KeyPath = ""
+	c.KeyPEM = []byte(id.Credentials.PrivateKey)
+
+	return nil
+}
+
+// Validate checks the config is complete and that the referenced certificate and key files are readable
+func (c Config) Validate() error {
+	var errs []error
+
+	if !c.TLSDisabled && c.TLSCertPath == "" && len(c.TLSCertPEM) == 0 {
+		errs = append(errs, fmt.Errorf("peer TLS certificate is required"))
+	}
+
+	if c.CertPath == "" && len(c.CertPEM) == 0 {
+		errs = append(errs, fmt.Errorf("certificate is required"))
+	}
+	if c.KeyPath == "" && len(c.KeyPEM) == 0 {
+		errs = append(errs, fmt.Errorf("private key is required"))
+	}
+
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"peer endpoint", c.PeerEndpoint},
+		{"MSP ID", c.MSPID},
+		{"channel name", c.ChannelName},
+		{"chaincode name", c.ChaincodeName},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", r.name))
+		}
+	}
+
+	files := []struct {
+		name string
+		path string
+	}{
+		{"peer TLS certificate", c.TLSCertPath},
+		{"connection profile", c.ConnectionProfilePath},
+		{"certificate", c.CertPath},
+		{"private key", c.KeyPath},
+	}
+	for _, f := range files {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.name, err))
+		}
+	}
+
+	if c.ConnectAttempts < 1 {
+		errs = append(errs, fmt.Errorf("connect attempts must be at least 1"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func stringFromEnv(name string, value *string) {
+	if v := os.Getenv(name); v != "" {
+		*value = v
+	}
+}
+
+// durationFromEnv reads a duration such as "30s" from the environment
+func durationFromEnv(name string, value *time.Duration) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+
+	duration, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", name, v, err)
+	}
+	*value = duration
+
+	return nil
+}
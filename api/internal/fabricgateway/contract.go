@@ -0,0 +1,72 @@
+package fabricgateway
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Contract calls the configured chaincode over the gateway's current connection, reconnecting when
+// the peer is unavailable so long-running commands outlive a peer restart
+type Contract struct {
+	gateway *Gateway
+}
+
+// Contract returns the configured chaincode contract, connecting on first use
+func (g *Gateway) Contract() *Contract {
+	return &Contract{gateway: g}
+}
+
+// IsUnavailable reports whether err is the peer, or the connection to it, being unavailable
+func IsUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// EvaluateWithContext is retried once on a new connection when the peer is unavailable
+func (c *Contract) EvaluateWithContext(ctx context.Context, transactionName string, options ...client.ProposalOption) ([]byte, error) {
+	return c.call(ctx, transactionName, func(contract *client.Contract) ([]byte, error) {
+		return contract.EvaluateWithContext(ctx, transactionName, options...)
+	}, func(error) bool { return true })
+}
+
+// SubmitWithContext is only retried when endorsement failed, once the transaction has gone to the
+// orderer it may commit even though the call failed
+func (c *Contract) SubmitWithContext(ctx context.Context, transactionName string, options ...client.ProposalOption) ([]byte, error) {
+	return c.call(ctx, transactionName, func(contract *client.Contract) ([]byte, error) {
+		return contract.SubmitWithContext(ctx, transactionName, options...)
+	}, func(err error) bool {
+		var endorseErr *client.EndorseError
+		return errors.As(err, &endorseErr)
+	})
+}
+
+func (c *Contract) call(ctx context.Context, transactionName string, invoke func(*client.Contract) ([]byte, error), retryable func(error) bool) ([]byte, error) {
+	network, err := c.gateway.GetNetwork(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := invoke(network.GetContract(c.gateway.config.ChaincodeName))
+	if err == nil || !IsUnavailable(err) {
+		return result, err
+	}
+
+	log.Printf("peer unavailable during %s, reconnecting: %v", transactionName, err)
+	if reconnectErr := c.gateway.Reconnect(ctx, network); reconnectErr != nil {
+		return nil, errors.Join(err, reconnectErr)
+	}
+	if !retryable(err) {
+		return nil, err
+	}
+
+	network, err = c.gateway.GetNetwork(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return invoke(network.GetContract(c.gateway.config.ChaincodeName))
+}
@@ -1,157 +1,354 @@
 package fabricgateway
 
 import (
+	"context"
 	"crypto/x509"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/hyperledger/fabric-gateway/pkg/client"
 	"github.com/hyperledger/fabric-gateway/pkg/identity"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 )
 
+var ErrNotConnected = errors.New("gateway is not connected")
+
+// ErrPeerUnreachable is returned once every connection attempt has failed
+var ErrPeerUnreachable = errors.New("peer unreachable")
+
 type Gateway struct {
+	config Config
+
+	mu         sync.Mutex
 	clientConn *grpc.ClientConn
 	gateway    *client.Gateway
+	// network is replaced on every connection, so it identifies the connection a caller used
+	network *client.Network
 }
 
-func NewGateway() *Gateway {
-	return &Gateway{}
+// NewGateway validates the config and returns an unconnected gateway. The config's connection
+// profile must already have been applied, ApplyEnv does so.
+func NewGateway(config Config) (*Gateway, error) {
+	if err := config.ApplyWallet(); err != nil {
+		return nil, fmt.Errorf("invalid wallet identity: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid gateway config: %w", err)
+	}
+
+	return &Gateway{
+		config: config,
+	}, nil
 }
 
-func (g *Gateway) GetContract() (*client.Contract, error) {
-	g.clientConn = newGrpcConnection()
+// Connect dials the peer, retrying with backoff, and does nothing if already connected
+func (g *Gateway) Connect(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	id := newIdentity()
-	sign := newSign()
+	if g.gateway != nil {
+		return nil
+	}
 
-	gw, err := client.Connect(
-		id,
-		client.WithSign(sign),
-		client.WithClientConnection(g.clientConn),
-	)
+	return g.connect(ctx)
+}
+
+// Reconnect closes the connection serving stale and dials the peer again, retrying with backoff.
+// It does nothing if that connection has already been replaced, so callers that failed together
+// reconnect once. Networks and contracts obtained before reconnecting must be fetched again.
+func (g *Gateway) Reconnect(ctx context.Context, stale *client.Network) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.network != nil && g.network != stale {
+		return nil
+	}
+
+	g.close()
+
+	return g.connect(ctx)
+}
+
+// GetContract connects if required and returns the configured chaincode contract
+func (g *Gateway) GetContract(ctx context.Context) (*client.Contract, error) {
+	network, err := g.GetNetwork(ctx)
 	if err != nil {
-		log.Fatalf("failed to connect gateway: %v", err)
+		return nil, err
 	}
-	g.gateway = gw
 
-	channelName := os.Getenv("CHANNEL_NAME")
-	if channelName == "" {
-		channelName = "channel"
+	return network.GetContract(g.config.ChaincodeName), nil
+}
+
+// GetNetwork connects if required and returns the configured channel
+func (g *Gateway) GetNetwork(ctx context.Context) (*client.Network, error) {
+	if err := g.Connect(ctx); err != nil {
+		return nil, err
 	}
-	network := gw.GetNetwork(channelName)
 
-	chaincodeName := os.Getenv("CHAINCODE_NAME")
-	if chaincodeName == "" {
-		chaincodeName = "chaincode"
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.network == nil {
+		return nil, ErrNotConnected
 	}
-	contract := network.GetContract(chaincodeName)
 
-	return contract, nil
+	return g.network, nil
 }
 
 func (g *Gateway) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.close()
+}
+
+func (g *Gateway) close() {
 	if g.gateway != nil {
 		g.gateway.Close()
+		g.gateway = nil
+		g.network = nil
 	}
 	if g.clientConn != nil {
 		g.clientConn.Close()
+		g.clientConn = nil
 	}
 }
 
-// newGrpcConnection creates a gRPC connection to the Fabric Gateway
-func newGrpcConnection() *grpc.ClientConn {
-	// Get peer endpoint from environment or use default
-	peerEndpoint := os.Getenv("PEER_ENDPOINT")
-	if peerEndpoint == "" {
-		peerEndpoint = "localhost:7051"
+// connect establishes the gRPC connection and gateway, the caller must hold the lock
+func (g *Gateway) connect(ctx context.Context) error {
+	id, err := newIdentity(g.config)
+	if err != nil {
+		return err
 	}
 
-	// Path to peer TLS certificate
-	tlsCertPath := os.Getenv("PEER_TLS_CERT")
-	if tlsCertPath == "" {
-		tlsCertPath = "./crypto-config/peers/peer.example.com/tls/ica.tls.organisation.cert"
+	sign, err := newSign(g.config)
+	if err != nil {
+		return err
 	}
 
-	certificate, err := os.ReadFile(tlsCertPath)
+	clientConn, err := g.dialWithBackoff(ctx)
 	if err != nil {
-		log.Fatalf("failed to read TLS certificate: %v", err)
+		return err
 	}
 
-	certPool := x509.NewCertPool()
-	if !certPool.AppendCertsFromPEM(certificate) {
-		log.Fatalf("failed to add certificate to pool")
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithClientConnection(clientConn),
+		client.WithEvaluateTimeout(g.config.EvaluateTimeout),
+		client.WithEndorseTimeout(g.config.EndorseTimeout),
+		client.WithSubmitTimeout(g.config.SubmitTimeout),
+		client.WithCommitStatusTimeout(g.config.CommitStatusTimeout),
+	)
+	if err != nil {
+		clientConn.Close()
+		return fmt.Errorf("failed to connect gateway: %w", err)
 	}
 
-	// Override server name for TLS verification to match certificate
-	// This allows connecting to localhost while using peer's certificate
-	tlsServerName := os.Getenv("TLS_SERVER_NAME")
+	g.clientConn = clientConn
+	g.gateway = gw
+	g.network = gw.GetNetwork(g.config.ChannelName)
+
+	return nil
+}
+
+// dialWithBackoff retries newGrpcConnection, doubling the delay between attempts
+func (g *Gateway) dialWithBackoff(ctx context.Context) (*grpc.ClientConn, error) {
+	backoff := g.config.ConnectBackoff
+
+	var lastErr error
+	for attempt := 1; attempt <= g.config.ConnectAttempts; attempt++ {
+		clientConn, err := newGrpcConnection(ctx, g.config)
+		if err == nil {
+			return clientConn, nil
+		}
+		lastErr = err
 
-	transportCredentials := credentials.NewClientTLSFromCert(certPool, tlsServerName)
+		if attempt == g.config.ConnectAttempts {
+			break
+		}
 
-	connection, err := grpc.NewClient(peerEndpoint, grpc.WithTransportCredentials(transportCredentials))
+		log.Printf("gateway connect attempt %d/%d failed, retrying in %s: %v", attempt, g.config.ConnectAttempts, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	return nil, fmt.Errorf("failed to connect to peer %s after %d attempts: %w: %w", g.config.PeerEndpoint, g.config.ConnectAttempts, ErrPeerUnreachable, lastErr)
+}
+
+// newGrpcConnection creates a gRPC connection to the Fabric Gateway and waits for it to become ready
+func newGrpcConnection(ctx context.Context, config Config) (*grpc.ClientConn, error) {
+	options, err := dialOptions(config)
 	if err != nil {
-		log.Fatalf("failed to create gRPC connection: %v", err)
+		return nil, err
+	}
+
+	connection, err := grpc.NewClient(config.PeerEndpoint, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection: %w", err)
+	}
+
+	if err := waitForReady(ctx, connection, config.ConnectTimeout); err != nil {
+		connection.Close()
+		return nil, err
 	}
 
-	return connection
+	return connection, nil
 }
 
-// newIdentity creates a client identity from certificate and MSP ID
-func newIdentity() *identity.X509Identity {
-	// Get MSP ID from environment or use default
-	mspID := os.Getenv("MSP_ID")
-	if mspID == "" {
-		mspID = "orgMSP"
+// dialOptions builds the transport credentials and call options for the peer connection
+func dialOptions(config Config) ([]grpc.DialOption, error) {
+	var options []grpc.DialOption
+
+	if config.TLSDisabled {
+		options = append(options, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	} else {
+		certificate := config.TLSCertPEM
+		if len(certificate) == 0 {
+			var err error
+			certificate, err = os.ReadFile(config.TLSCertPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read TLS certificate: %w", err)
+			}
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(certificate) {
+			return nil, fmt.Errorf("failed to add certificate to pool")
+		}
+
+		options = append(options, grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(certPool, config.TLSServerName)))
+	}
+
+	if config.KeepaliveTime > 0 {
+		options = append(options, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                config.KeepaliveTime,
+			Timeout:             config.KeepaliveTimeout,
+			PermitWithoutStream: config.KeepalivePermit,
+		}))
+	}
+
+	var callOptions []grpc.CallOption
+	if config.MaxReceiveMessageSize > 0 {
+		callOptions = append(callOptions, grpc.MaxCallRecvMsgSize(config.MaxReceiveMessageSize))
+	}
+	if config.MaxSendMessageSize > 0 {
+		callOptions = append(callOptions, grpc.MaxCallSendMsgSize(config.MaxSendMessageSize))
 	}
+	if len(callOptions) > 0 {
+		options = append(options, grpc.WithDefaultCallOptions(callOptions...))
+	}
+
+	return options, nil
+}
 
-	// Path to user certificate
-	certPath := os.Getenv("CERT_PATH")
-	if certPath == "" {
-		certPath = "./crypto-config/users/user/msp/signcerts/cert.pem"
+// waitForReady triggers the lazy gRPC connection and blocks until it is ready or the timeout expires
+func waitForReady(ctx context.Context, connection *grpc.ClientConn, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	connection.Connect()
+	for {
+		state := connection.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !connection.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection not ready (state %s): %w", state, ctx.Err())
+		}
 	}
+}
 
-	certificatePEM, err := os.ReadFile(certPath)
+// Signer is the gateway's identity, for signing data outside of transactions
+type Signer struct {
+	MSPID       string
+	Certificate *x509.Certificate
+	// Sign takes a SHA-256 digest for ECDSA keys and the whole message for Ed25519 keys, as the
+	// gateway itself does
+	Sign identity.Sign
+}
+
+// Signer loads the identity's certificate and private key, it does not need a connection
+func (g *Gateway) Signer() (*Signer, error) {
+	id, err := newIdentity(g.config)
 	if err != nil {
-		log.Fatalf("failed to read certificate: %v", err)
+		return nil, err
 	}
 
-	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	certificate, err := identity.CertificateFromPEM(id.Credentials())
 	if err != nil {
-		log.Fatalf("failed to parse certificate: %v", err)
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
 	}
 
-	id, err := identity.NewX509Identity(mspID, certificate)
+	sign, err := newSign(g.config)
 	if err != nil {
-		log.Fatalf("failed to create identity: %v", err)
+		return nil, err
 	}
 
-	return id
+	return &Signer{
+		MSPID:       id.MspID(),
+		Certificate: certificate,
+		Sign:        sign,
+	}, nil
 }
 
-// newSign creates a signing function using the private key
-func newSign() identity.Sign {
-	// Path to user private key
-	keyPath := os.Getenv("KEY_PATH")
-	if keyPath == "" {
-		keyPath = "./crypto-config/users/user/msp/keystore/key_sk"
+// newIdentity creates a client identity from certificate and MSP ID
+func newIdentity(config Config) (*identity.X509Identity, error) {
+	certificatePEM := config.CertPEM
+	if len(certificatePEM) == 0 {
+		var err error
+		certificatePEM, err = os.ReadFile(config.CertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate: %w", err)
+		}
 	}
 
-	privateKeyPEM, err := os.ReadFile(keyPath)
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
 	if err != nil {
-		log.Fatalf("failed to read private key: %v", err)
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	id, err := identity.NewX509Identity(config.MSPID, certificate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	return id, nil
+}
+
+// newSign creates a signing function using the private key
+func newSign(config Config) (identity.Sign, error) {
+	privateKeyPEM := config.KeyPEM
+	if len(privateKeyPEM) == 0 {
+		var err error
+		privateKeyPEM, err = os.ReadFile(config.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key: %w", err)
+		}
 	}
 
 	privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)
 	if err != nil {
-		log.Fatalf("failed to parse private key: %v", err)
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
 	}
 
 	sign, err := identity.NewPrivateKeySign(privateKey)
 	if err != nil {
-		log.Fatalf("failed to create sign function: %v", err)
+		return nil, fmt.Errorf("failed to create sign function: %w", err)
 	}
 
-	return sign
+	return sign, nil
 }
@@ -0,0 +1,205 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelpb"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// Client is the subset of personnelclient.PersonnelClient the service exposes
+type Client interface {
+	GetPersonnel(ctx context.Context, personnelID string) (*domain.Personnel, error)
+	EnrollCadet(ctx context.Context, personnelID, name, campus string) (*domain.Personnel, error)
+	CompleteTraining(ctx context.Context, recordID, personnelID, campus, trainingCode, completedAt, issuedBy string) (*domain.Training, error)
+}
+
+// EventSource is implemented by personnelclient.EventWatcher
+type EventSource interface {
+	Watch(ctx context.Context, startBlock *uint64) (<-chan *personnelclient.Event, error)
+}
+
+// Service implements PersonnelService on top of the personnel client
+type Service struct {
+	personnelpb.UnimplementedPersonnelServiceServer
+
+	client Client
+	events EventSource
+}
+
+func NewService(client Client, events EventSource) *Service {
+	return &Service{
+		client: client,
+		events: events,
+	}
+}
+
+// NewServer returns a gRPC server with the service and server reflection registered
+func NewServer(service *Service, options ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(options...)
+	personnelpb.RegisterPersonnelServiceServer(server, service)
+	reflection.Register(server)
+
+	return server
+}
+
+// Serve blocks until the server stops, returning nil after GracefulStop or Stop
+func Serve(server *grpc.Server, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("serving gRPC API on %s", listener.Addr())
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) GetPersonnel(ctx context.Context, request *personnelpb.GetPersonnelRequest) (*personnelpb.Personnel, error) {
+	personnel, err := s.client.GetPersonnel(ctx, request.GetPersonnelId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPersonnel(personnel), nil
+}
+
+func (s *Service) EnrollCadet(ctx context.Context, request *personnelpb.EnrollCadetRequest) (*personnelpb.Personnel, error) {
+	enrolment := domain.CadetEnrolment{
+		PersonnelID: request.GetPersonnelId(),
+		Name:        request.GetName(),
+		Campus:      request.GetCampus(),
+	}
+	if err := personnelclient.ValidateEnrolment(enrolment); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	personnel, err := s.client.EnrollCadet(ctx, enrolment.PersonnelID, enrolment.Name, enrolment.Campus)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPersonnel(personnel), nil
+}
+
+func (s *Service) CompleteTraining(ctx context.Context, request *personnelpb.CompleteTrainingRequest) (*personnelpb.Training, error) {
+	completion := domain.TrainingCompletion{
+		RecordID:     request.GetRecordId(),
+		PersonnelID:  request.GetPersonnelId(),
+		Campus:       request.GetCampus(),
+		TrainingCode: request.GetTrainingCode(),
+		CompletedAt:  request.GetCompletedAt(),
+		IssuedBy:     request.GetIssuedBy(),
+	}
+	if err := personnelclient.ValidateTraining(completion); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	training, err := s.client.CompleteTraining(ctx, completion.RecordID, completion.PersonnelID, completion.Campus, completion.TrainingCode, completion.CompletedAt, completion.IssuedBy)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toTraining(training), nil
+}
+
+func (s *Service) WatchEvents(request *personnelpb.WatchEventsRequest, stream grpc.ServerStreamingServer[personnelpb.Event]) error {
+	ctx := stream.Context()
+
+	var startBlock *uint64
+	if request.StartBlock != nil {
+		block := request.GetStartBlock()
+		startBlock = &block
+	}
+
+	events, err := s.events.Watch(ctx, startBlock)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	for event := range events {
+		message := &personnelpb.Event{
+			BlockNumber:   event.BlockNumber,
+			TransactionId: event.TransactionID,
+		}
+
+		switch {
+		case event.Personnel != nil:
+			message.Payload = &personnelpb.Event_CadetEnrolled{
+				CadetEnrolled: &personnelpb.CadetEnrolled{Personnel: toPersonnel(event.Personnel)},
+			}
+		case event.Training != nil:
+			message.Payload = &personnelpb.Event_TrainingCompleted{
+				TrainingCompleted: &personnelpb.TrainingCompleted{Training: toTraining(event.Training)},
+			}
+		default:
+			continue
+		}
+
+		if err := stream.Send(message); err != nil {
+			return err
+		}
+	}
+
+	// The events channel also closes when the peer connection fails, which the caller should retry
+	if ctx.Err() == nil {
+		return status.Error(codes.Unavailable, personnelclient.ErrEventStreamEnded.Error())
+	}
+	return nil
+}
+
+// toStatus maps a client or contract error onto a gRPC status with the contract's own message
+func toStatus(err error) error {
+	code := codes.Internal
+	switch personnelclient.Classify(err) {
+	case personnelclient.ErrorInvalid:
+		code = codes.InvalidArgument
+	case personnelclient.ErrorNotFound:
+		code = codes.NotFound
+	case personnelclient.ErrorConflict:
+		code = codes.AlreadyExists
+	case personnelclient.ErrorDenied:
+		code = codes.PermissionDenied
+	case personnelclient.ErrorUnavailable:
+		code = codes.Unavailable
+	case personnelclient.ErrorTimeout:
+		code = codes.DeadlineExceeded
+	default:
+		log.Printf("request failed: %v", err)
+	}
+
+	return status.Error(code, personnelclient.ContractMessage(err))
+}
+
+func toPersonnel(personnel *domain.Personnel) *personnelpb.Personnel {
+	return &personnelpb.Personnel{
+		PersonnelId: personnel.PersonnelID,
+		Name:        personnel.Name,
+		Rank:        personnel.Rank,
+		Campus:      personnel.Campus,
+		Status:      personnel.Status,
+	}
+}
+
+func toTraining(training *domain.Training) *personnelpb.Training {
+	return &personnelpb.Training{
+		RecordId:     training.RecordID,
+		PersonnelId:  training.PersonnelID,
+		Campus:       training.Campus,
+		TrainingCode: training.TrainingCode,
+		CompletedAt:  training.CompletedAt,
+		IssuedBy:     training.IssuedBy,
+		Status:       training.Status,
+	}
+}
@@ -0,0 +1,131 @@
+package httpapi
+
+import (
+	"net/http"
+)
+
+// Where an HTTP request carries a transaction parameter
+const (
+	InPath  = "path"
+	InQuery = "query"
+	InBody  = "body"
+)
+
+// Route maps an HTTP endpoint onto the contract transaction it calls. The OpenAPI generator reads
+// the same table, so the documented endpoints are always the served ones.
+type Route struct {
+	Method  string
+	Path    string
+	Summary string
+
+	// Transaction is the `Contract:Function` called by the route
+	Transaction string
+	// Params names the transaction's parameters in order and where the request carries each. The
+	// contract metadata only knows them by position, path wildcards use the same names.
+	Params []Param
+	// Status is the response status on success
+	Status int
+	// Produces lists the media types the route can respond with besides JSON, chosen by the Accept header
+	Produces []string
+
+	handle func(s *Server, w http.ResponseWriter, r *http.Request)
+}
+
+type Param struct {
+	Name string
+	In   string
+}
+
+// Routes returns every endpoint served by the API, excluding the health check
+func Routes() []Route {
+	return []Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/personnel/{personnelID}",
+			Summary:     "Get a member of personnel",
+			Transaction: "PersonnelContract:GetPersonnel",
+			Params:      []Param{{"personnelID", InPath}},
+			Status:      http.StatusOK,
+			handle:      (*Server).handleGetPersonnel,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/personnel",
+			Summary:     "Enroll a cadet",
+			Transaction: "PersonnelContract:EnrollCadet",
+			Params:      []Param{{"personnelID", InBody}, {"name", InBody}, {"campus", InBody}},
+			Status:      http.StatusCreated,
+			handle:      (*Server).handleEnrollCadet,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/personnel/{personnelID}/training",
+			Summary:     "Get a member of personnel's training history, oldest first",
+			Transaction: "TrainingContract:GetTrainingHistory",
+			Params:      []Param{{"personnelID", InPath}},
+			Status:      http.StatusOK,
+			handle:      (*Server).handleGetTrainingHistory,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/personnel/{personnelID}/transcript",
+			Summary:     "Get a member of personnel's transcript, with the transaction behind each line",
+			Transaction: "PersonnelContract:GetTranscript",
+			Params:      []Param{{"personnelID", InPath}},
+			Status:      http.StatusOK,
+			Produces:    []string{"text/html", "text/plain"},
+			handle:      (*Server).handleGetTranscript,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/training",
+			Summary:     "Find the training records for a training code",
+			Transaction: "TrainingContract:GetTrainingByCode",
+			Params:      []Param{{"code", InQuery}},
+			Status:      http.StatusOK,
+			handle:      (*Server).handleGetTrainingByCode,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/training",
+			Summary:     "Record a completed training",
+			Transaction: "TrainingContract:CompleteTraining",
+			Params: []Param{
+				{"recordID", InBody},
+				{"personnelID", InBody},
+				{"campus", InBody},
+				{"trainingCode", InBody},
+				{"completedAt", InBody},
+				{"issuedBy", InBody},
+			},
+			Status: http.StatusCreated,
+			handle: (*Server).handleCompleteTraining,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/training/{recordID}",
+			Summary:     "Get a training record",
+			Transaction: "TrainingContract:GetTraining",
+			Params:      []Param{{"recordID", InPath}},
+			Status:      http.StatusOK,
+			handle:      (*Server).handleGetTraining,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/courses",
+			Summary:     "List the course catalogue",
+			Transaction: "CatalogueContract:ListCourses",
+			Status:      http.StatusOK,
+			handle:      (*Server).handleListCourses,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/courses/{trainingCode}",
+			Summary:     "Get a course",
+			Transaction: "CatalogueContract:GetCourse",
+			Params:      []Param{{"trainingCode", InPath}},
+			Status:      http.StatusOK,
+			handle:      (*Server).handleGetCourse,
+		},
+	}
+}
@@ -0,0 +1,321 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/transcript"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+)
+
+// maxBodyBytes bounds request bodies, every endpoint accepts a single small JSON object
+const maxBodyBytes = 1 << 20
+
+// Client is the subset of personnelclient.PersonnelClient the server exposes
+type Client interface {
+	GetPersonnel(ctx context.Context, personnelID string) (*domain.Personnel, error)
+	EnrollCadet(ctx context.Context, personnelID, name, campus string) (*domain.Personnel, error)
+	CompleteTraining(ctx context.Context, recordID, personnelID, campus, trainingCode, completedAt, issuedBy string) (*domain.Training, error)
+	GetTraining(ctx context.Context, recordID string) (*domain.Training, error)
+	GetTrainingHistory(ctx context.Context, personnelID string) ([]*domain.Training, error)
+	GetTranscript(ctx context.Context, personnelID string) (*domain.Transcript, error)
+	GetTrainingByCode(ctx context.Context, trainingCode string) ([]*domain.Training, error)
+	GetCourse(ctx context.Context, trainingCode string) (*domain.Course, error)
+	ListCourses(ctx context.Context) ([]*domain.Course, error)
+}
+
+// Server exposes the personnel client over HTTP/JSON
+type Server struct {
+	client Client
+	server *http.Server
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer serves plain HTTP when tlsConfig is nil, otherwise HTTPS with tlsConfig's certificates
+func NewServer(addr string, client Client, tlsConfig *tls.Config) *Server {
+	s := &Server{
+		client: client,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	for _, route := range Routes() {
+		handle := route.handle
+		mux.HandleFunc(route.Method+" "+route.Path, func(w http.ResponseWriter, r *http.Request) {
+			handle(s, w, r)
+		})
+	}
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           logRequests(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConfig,
+	}
+
+	return s
+}
+
+// ListenAndServe blocks until the server fails or is shut down, returning nil after Shutdown
+func (s *Server) ListenAndServe() error {
+	var err error
+	if s.server.TLSConfig != nil {
+		log.Printf("serving HTTPS API on %s", s.server.Addr)
+		err = s.server.ListenAndServeTLS("", "")
+	} else {
+		log.Printf("serving HTTP API on %s", s.server.Addr)
+		err = s.server.ListenAndServe()
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting requests and waits for in-flight ones until ctx is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleGetPersonnel(w http.ResponseWriter, r *http.Request) {
+	personnel, err := s.client.GetPersonnel(r.Context(), r.PathValue("personnelID"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, personnel)
+}
+
+func (s *Server) handleEnrollCadet(w http.ResponseWriter, r *http.Request) {
+	var request domain.CadetEnrolment
+	if !readJSON(w, r, &request) {
+		return
+	}
+	if err := personnelclient.ValidateEnrolment(request); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	personnel, err := s.client.EnrollCadet(r.Context(), request.PersonnelID, request.Name, request.Campus)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/personnel/"+personnel.PersonnelID)
+	writeJSON(w, http.StatusCreated, personnel)
+}
+
+func (s *Server) handleGetTrainingHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := s.client.GetTrainingHistory(r.Context(), r.PathValue("personnelID"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (s *Server) handleGetTranscript(w http.ResponseWriter, r *http.Request) {
+	record, err := s.client.GetTranscript(r.Context(), r.PathValue("personnelID"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch preferredType(r, "text/html", "text/plain") {
+	case "text/html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = transcript.WriteHTML(w, record, time.Now())
+	case "text/plain":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		err = transcript.WriteText(w, record, time.Now())
+	default:
+		writeJSON(w, http.StatusOK, record)
+	}
+	if err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+// preferredType returns the first of the offered media types named in the Accept header, or "" for
+// JSON. Quality values are not weighed, browsers list text/html first anyway.
+func preferredType(r *http.Request, offered ...string) string {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if mediaType == "application/json" {
+			return ""
+		}
+		for _, offer := range offered {
+			if mediaType == offer {
+				return offer
+			}
+		}
+	}
+	return ""
+}
+
+func (s *Server) handleGetTrainingByCode(w http.ResponseWriter, r *http.Request) {
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "code query parameter is required"})
+		return
+	}
+
+	records, err := s.client.GetTrainingByCode(r.Context(), code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, records)
+}
+
+func (s *Server) handleCompleteTraining(w http.ResponseWriter, r *http.Request) {
+	var request domain.TrainingCompletion
+	if !readJSON(w, r, &request) {
+		return
+	}
+	if err := personnelclient.ValidateTraining(request); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	training, err := s.client.CompleteTraining(r.Context(), request.RecordID, request.PersonnelID, request.Campus, request.TrainingCode, request.CompletedAt, request.IssuedBy)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/training/"+training.RecordID)
+	writeJSON(w, http.StatusCreated, training)
+}
+
+func (s *Server) handleGetTraining(w http.ResponseWriter, r *http.Request) {
+	training, err := s.client.GetTraining(r.Context(), r.PathValue("recordID"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, training)
+}
+
+func (s *Server) handleListCourses(w http.ResponseWriter, r *http.Request) {
+	courses, err := s.client.ListCourses(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, courses)
+}
+
+func (s *Server) handleGetCourse(w http.ResponseWriter, r *http.Request) {
+	course, err := s.client.GetCourse(r.Context(), r.PathValue("trainingCode"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, course)
+}
+
+// readJSON decodes a request body holding exactly one JSON object with no unknown fields, writing
+// a 400 response and returning false if it does not
+func readJSON(w http.ResponseWriter, r *http.Request, target any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(target); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid request body: %v", err)})
+		return false
+	}
+	if decoder.More() {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body: expected a single JSON object"})
+		return false
+	}
+
+	return true
+}
+
+// writeError maps a client or contract error onto an HTTP status with the contract's own message
+func writeError(w http.ResponseWriter, err error) {
+	status := statusFor(personnelclient.Classify(err))
+	if status == http.StatusInternalServerError {
+		log.Printf("request failed: %v", err)
+	}
+
+	writeJSON(w, status, errorResponse{Error: personnelclient.ContractMessage(err)})
+}
+
+func statusFor(kind personnelclient.ErrorKind) int {
+	switch kind {
+	case personnelclient.ErrorInvalid:
+		return http.StatusBadRequest
+	case personnelclient.ErrorNotFound:
+		return http.StatusNotFound
+	case personnelclient.ErrorConflict:
+		return http.StatusConflict
+	case personnelclient.ErrorDenied:
+		return http.StatusForbidden
+	case personnelclient.ErrorUnavailable:
+		return http.StatusServiceUnavailable
+	case personnelclient.ErrorTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+// statusRecorder captures the status code written by a handler for the request log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, recorder.status, time.Since(started).Round(time.Millisecond))
+	})
+}
@@ -0,0 +1,272 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/httpapi"
+)
+
+// Version is the OpenAPI version generated, 3.1 so the contract's JSON schemas can be used unchanged
+const Version = "3.1.0"
+
+// errorSchema is the body of every error response written by the HTTP API
+const errorSchema = "Error"
+
+// Document is an OpenAPI 3 document. Schemas are kept as decoded JSON as they come from the
+// contract metadata untyped.
+type Document struct {
+	OpenAPI    string                          `json:"openapi" yaml:"openapi"`
+	Info       Info                            `json:"info" yaml:"info"`
+	Paths      map[string]map[string]Operation `json:"paths" yaml:"paths"`
+	Components Components                      `json:"components" yaml:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId" yaml:"operationId"`
+	Summary     string               `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses" yaml:"responses"`
+	// Transaction records the chaincode transaction behind the operation and whether it is submitted
+	Transaction Transaction `json:"x-fabric-transaction" yaml:"x-fabric-transaction"`
+}
+
+type Transaction struct {
+	Name   string `json:"name" yaml:"name"`
+	Submit bool   `json:"submit" yaml:"submit"`
+}
+
+type Parameter struct {
+	Name     string         `json:"name" yaml:"name"`
+	In       string         `json:"in" yaml:"in"`
+	Required bool           `json:"required" yaml:"required"`
+	Schema   map[string]any `json:"schema" yaml:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required" yaml:"required"`
+	Content  map[string]MediaType `json:"content" yaml:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description" yaml:"description"`
+	Content     map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema map[string]any `json:"schema" yaml:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]map[string]any `json:"schemas" yaml:"schemas"`
+}
+
+// metadata is the part of the contractapi chaincode metadata, as returned by
+// org.hyperledger.fabric:GetMetadata, that the generator reads
+type metadata struct {
+	Info      Info `json:"info"`
+	Contracts map[string]struct {
+		Transactions []transactionMetadata `json:"transactions"`
+	} `json:"contracts"`
+	Components struct {
+		Schemas map[string]map[string]any `json:"schemas"`
+	} `json:"components"`
+}
+
+type transactionMetadata struct {
+	Name       string   `json:"name"`
+	Tag        []string `json:"tag"`
+	Parameters []struct {
+		Name   string         `json:"name"`
+		Schema map[string]any `json:"schema"`
+	} `json:"parameters"`
+	Returns map[string]any `json:"returns"`
+}
+
+var pathParameter = regexp.MustCompile(`\{([^}]+)\}`)
+
+// errorResponses are the statuses the HTTP API maps contract and gateway errors onto
+var errorResponses = map[int]string{
+	http.StatusBadRequest:          "Invalid request or parameters",
+	http.StatusForbidden:           "Caller's identity may not call the transaction",
+	http.StatusNotFound:            "Record does not exist",
+	http.StatusConflict:            "Record already exists or conflicts with the ledger",
+	http.StatusInternalServerError: "Unexpected error",
+	http.StatusServiceUnavailable:  "Fabric gateway unavailable",
+	http.StatusGatewayTimeout:      "Fabric gateway timed out",
+}
+
+// Generate builds the document for the routes from the chaincode metadata JSON. Parameter and
+// response schemas come from the metadata, so a route no longer matching its transaction's
+// signature is an error rather than stale documentation.
+func Generate(metadataJSON []byte, routes []httpapi.Route, info Info) (*Document, error) {
+	var meta metadata
+	if err := json.Unmarshal(metadataJSON, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse contract metadata: %w", err)
+	}
+	if len(meta.Contracts) == 0 {
+		return nil, fmt.Errorf("contract metadata has no contracts")
+	}
+
+	if info.Version == "" {
+		info.Version = meta.Info.Version
+	}
+
+	document := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]map[string]Operation{},
+		Components: Components{
+			Schemas: map[string]map[string]any{
+				errorSchema: {
+					"type":                 "object",
+					"properties":           map[string]any{"error": map[string]any{"type": "string"}},
+					"required":             []string{"error"},
+					"additionalProperties": false,
+				},
+			},
+		},
+	}
+
+	for name, schema := range meta.Components.Schemas {
+		document.Components.Schemas[name] = normalizeSchema(schema).(map[string]any)
+	}
+
+	for _, route := range routes {
+		contractName, function, found := strings.Cut(route.Transaction, ":")
+		if !found {
+			return nil, fmt.Errorf("route %s %s: transaction %s is not of the form Contract:Function", route.Method, route.Path, route.Transaction)
+		}
+
+		contract, ok := meta.Contracts[contractName]
+		if !ok {
+			return nil, fmt.Errorf("route %s %s: contract %s not in metadata", route.Method, route.Path, contractName)
+		}
+		index := slices.IndexFunc(contract.Transactions, func(t transactionMetadata) bool { return t.Name == function })
+		if index == -1 {
+			return nil, fmt.Errorf("route %s %s: transaction %s not in metadata", route.Method, route.Path, route.Transaction)
+		}
+		transaction := contract.Transactions[index]
+
+		if len(transaction.Parameters) != len(route.Params) {
+			return nil, fmt.Errorf("route %s %s names %d parameters but %s takes %d", route.Method, route.Path, len(route.Params), route.Transaction, len(transaction.Parameters))
+		}
+
+		operation := Operation{
+			OperationID: strings.ToLower(function[:1]) + function[1:],
+			Summary:     route.Summary,
+			Tags:        []string{contractName},
+			Responses:   map[string]*Response{},
+			Transaction: Transaction{
+				Name:   route.Transaction,
+				Submit: slices.Contains(transaction.Tag, "submit"),
+			},
+		}
+
+		body := map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{},
+			"required":             []string{},
+			"additionalProperties": false,
+		}
+		for i, param := range route.Params {
+			schema := normalizeSchema(transaction.Parameters[i].Schema).(map[string]any)
+
+			switch param.In {
+			case httpapi.InPath, httpapi.InQuery:
+				operation.Parameters = append(operation.Parameters, Parameter{
+					Name:     param.Name,
+					In:       param.In,
+					Required: true,
+					Schema:   schema,
+				})
+			case httpapi.InBody:
+				body["properties"].(map[string]any)[param.Name] = schema
+				body["required"] = append(body["required"].([]string), param.Name)
+			default:
+				return nil, fmt.Errorf("route %s %s: parameter %s has unknown location %s", route.Method, route.Path, param.Name, param.In)
+			}
+		}
+
+		for _, match := range pathParameter.FindAllStringSubmatch(route.Path, -1) {
+			if !slices.ContainsFunc(route.Params, func(p httpapi.Param) bool { return p.Name == match[1] && p.In == httpapi.InPath }) {
+				return nil, fmt.Errorf("route %s %s: path wildcard %s is not a parameter", route.Method, route.Path, match[1])
+			}
+		}
+
+		if len(body["required"].([]string)) > 0 {
+			operation.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: body}},
+			}
+		}
+
+		success := &Response{Description: http.StatusText(route.Status)}
+		if transaction.Returns != nil {
+			success.Content = map[string]MediaType{"application/json": {Schema: normalizeSchema(transaction.Returns).(map[string]any)}}
+			// Renderings of the same result, as a document rather than data
+			for _, mediaType := range route.Produces {
+				success.Content[mediaType] = MediaType{Schema: map[string]any{"type": "string"}}
+			}
+		}
+		operation.Responses[strconv.Itoa(route.Status)] = success
+
+		for status, description := range errorResponses {
+			operation.Responses[strconv.Itoa(status)] = &Response{
+				Description: description,
+				Content:     map[string]MediaType{"application/json": {Schema: map[string]any{"$ref": "#/components/schemas/" + errorSchema}}},
+			}
+		}
+
+		if document.Paths[route.Path] == nil {
+			document.Paths[route.Path] = map[string]Operation{}
+		}
+		document.Paths[route.Path][strings.ToLower(route.Method)] = operation
+	}
+
+	return document, nil
+}
+
+// normalizeSchema returns a copy of a metadata schema that is valid in OpenAPI. contractapi gives
+// component schemas an $id and refers to other components by bare name inside them.
+func normalizeSchema(schema any) any {
+	switch value := schema.(type) {
+	case map[string]any:
+		normalized := make(map[string]any, len(value))
+		for key, item := range value {
+			switch {
+			case key == "$id":
+				continue
+			case key == "$ref":
+				if ref, ok := item.(string); ok && !strings.HasPrefix(ref, "#/") {
+					item = "#/components/schemas/" + ref
+				}
+			default:
+				item = normalizeSchema(item)
+			}
+			normalized[key] = item
+		}
+		return normalized
+	case []any:
+		normalized := make([]any, len(value))
+		for i, item := range value {
+			normalized[i] = normalizeSchema(item)
+		}
+		return normalized
+	default:
+		return value
+	}
+}
@@ -1,32 +1,41 @@
 package personnelclient
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
 	"github.com/hyperledger/fabric-gateway/pkg/client"
 )
 
+// Contract is the chaincode contract the client calls, a *client.Contract or a
+// fabricgateway.Contract that reconnects
+type Contract interface {
+	EvaluateWithContext(ctx context.Context, transactionName string, options ...client.ProposalOption) ([]byte, error)
+	SubmitWithContext(ctx context.Context, transactionName string, options ...client.ProposalOption) ([]byte, error)
+}
+
 type PersonnelClient struct {
-	contract *client.Contract
+	contract Contract
 }
 
 var ErrInvalidPersonnelID = fmt.Errorf("invalid personnel ID")
 
-func NewPersonnelClient(contract *client.Contract) *PersonnelClient {
+func NewPersonnelClient(contract Contract) *PersonnelClient {
 	return &PersonnelClient{
 		contract: contract,
 	}
 }
 
-func (c *PersonnelClient) GetPersonnel(personnelID string) (*domain.Personnel, error) {
+func (c *PersonnelClient) GetPersonnel(ctx context.Context, personnelID string) (*domain.Personnel, error) {
 	if personnelID == "" {
 		return nil, ErrInvalidPersonnelID
 	}
 
-	result, err := c.contract.EvaluateTransaction("PersonnelContract:GetPersonnel", personnelID)
+	result, err := c.contract.EvaluateWithContext(ctx, "PersonnelContract:GetPersonnel", client.WithArguments(personnelID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
 	}
@@ -39,12 +48,31 @@ func (c *PersonnelClient) GetPersonnel(personnelID string) (*domain.Personnel, e
 	return personnel, nil
 }
 
-func (c *PersonnelClient) EnrollCadet(personnelID, name, campus string) (*domain.Personnel, error) {
+// GetTranscript needs the peer's history database, the transcript's transaction IDs are read from it
+func (c *PersonnelClient) GetTranscript(ctx context.Context, personnelID string) (*domain.Transcript, error) {
+	if personnelID == "" {
+		return nil, ErrInvalidPersonnelID
+	}
+
+	result, err := c.contract.EvaluateWithContext(ctx, "PersonnelContract:GetTranscript", client.WithArguments(personnelID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var transcript *domain.Transcript
+	if err := json.Unmarshal(result, &transcript); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return transcript, nil
+}
+
+func (c *PersonnelClient) EnrollCadet(ctx context.Context, personnelID, name, campus string) (*domain.Personnel, error) {
 	if personnelID == "" {
 		return nil, ErrInvalidPersonnelID
 	}
 
-	result, err := c.contract.SubmitTransaction("PersonnelContract:EnrollCadet", personnelID, name, campus)
+	result, err := c.contract.SubmitWithContext(ctx, "PersonnelContract:EnrollCadet", client.WithArguments(personnelID, name, campus))
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit transaction: %w", err)
 	}
@@ -57,49 +85,378 @@ func (c *PersonnelClient) EnrollCadet(personnelID, name, campus string) (*domain
 	return personnel, nil
 }
 
-func (c *PersonnelClient) CompleteTraining(recordID, personnelID, campus, trainingCode, completedAt, issuedBy string) (*domain.Training, error) {
-	// Parameter validation
+func (c *PersonnelClient) CompleteTraining(ctx context.Context, recordID, personnelID, campus, trainingCode, completedAt, issuedBy string) (*domain.Training, error) {
+	completion := domain.TrainingCompletion{
+		RecordID:     recordID,
+		PersonnelID:  personnelID,
+		Campus:       campus,
+		TrainingCode: trainingCode,
+		CompletedAt:  completedAt,
+		IssuedBy:     issuedBy,
+	}
+	if err := ValidateTraining(completion); err != nil {
+		return nil, err
+	}
+
+	result, err := c.contract.SubmitWithContext(
+		ctx,
+		"TrainingContract:CompleteTraining",
+		client.WithArguments(
+			recordID,
+			personnelID,
+			campus,
+			trainingCode,
+			completedAt,
+			issuedBy,
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit transaction: %w", err)
+	}
+
+	var training *domain.Training
+	if err := json.Unmarshal(result, &training); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return training, nil
+}
+
+func (c *PersonnelClient) GetTraining(ctx context.Context, recordID string) (*domain.Training, error) {
+	if recordID == "" {
+		return nil, fmt.Errorf("recordID is required")
+	}
+
+	result, err := c.contract.EvaluateWithContext(ctx, "TrainingContract:GetTraining", client.WithArguments(recordID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var training *domain.Training
+	if err := json.Unmarshal(result, &training); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return training, nil
+}
+
+// GetTrainingRevision returns the training record with the ID of the transaction that last wrote it
+func (c *PersonnelClient) GetTrainingRevision(ctx context.Context, recordID string) (*domain.TrainingRevision, error) {
 	if recordID == "" {
 		return nil, fmt.Errorf("recordID is required")
 	}
+
+	result, err := c.contract.EvaluateWithContext(ctx, "TrainingContract:GetTrainingRevision", client.WithArguments(recordID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var revision *domain.TrainingRevision
+	if err := json.Unmarshal(result, &revision); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return revision, nil
+}
+
+// GetTrainingHistory returns the personnel's training records ordered by completion time
+func (c *PersonnelClient) GetTrainingHistory(ctx context.Context, personnelID string) ([]*domain.Training, error) {
 	if personnelID == "" {
 		return nil, ErrInvalidPersonnelID
 	}
+
+	result, err := c.contract.EvaluateWithContext(ctx, "TrainingContract:GetTrainingHistory", client.WithArguments(personnelID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var history []*domain.Training
+	if err := json.Unmarshal(result, &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return history, nil
+}
+
+func (c *PersonnelClient) GetTrainingByCode(ctx context.Context, trainingCode string) ([]*domain.Training, error) {
+	if trainingCode == "" {
+		return nil, fmt.Errorf("trainingCode is required")
+	}
+
+	result, err := c.contract.EvaluateWithContext(ctx, "TrainingContract:GetTrainingByCode", client.WithArguments(trainingCode))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var records []*domain.Training
+	if err := json.Unmarshal(result, &records); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return records, nil
+}
+
+func (c *PersonnelClient) AddCourse(ctx context.Context, trainingCode, title, campus, description string) (*domain.Course, error) {
+	if trainingCode == "" {
+		return nil, fmt.Errorf("trainingCode is required")
+	}
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
 	if campus == "" {
 		return nil, fmt.Errorf("campus is required")
 	}
+
+	result, err := c.contract.SubmitWithContext(ctx, "CatalogueContract:AddCourse", client.WithArguments(trainingCode, title, campus, description))
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit transaction: %w", err)
+	}
+
+	var course *domain.Course
+	if err := json.Unmarshal(result, &course); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return course, nil
+}
+
+func (c *PersonnelClient) GetCourse(ctx context.Context, trainingCode string) (*domain.Course, error) {
 	if trainingCode == "" {
 		return nil, fmt.Errorf("trainingCode is required")
 	}
-	if completedAt == "" {
-		return nil, fmt.Errorf("completedAt is required")
+
+	result, err := c.contract.EvaluateWithContext(ctx, "CatalogueContract:GetCourse", client.WithArguments(trainingCode))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
 	}
-	if issuedBy == "" {
-		return nil, fmt.Errorf("issuedBy is required")
+
+	var course *domain.Course
+	if err := json.Unmarshal(result, &course); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
 	}
 
-	// Check for valid completedAt format (ISO 8601)
-	if _, err := time.Parse(time.RFC3339, completedAt); err != nil {
-		return nil, fmt.Errorf("completedAt must be in ISO 8601 / RFC3339 format: %w", err)
-	}
-
-	result, err := c.contract.SubmitTransaction(
-		"PersonnelContract:CompleteTraining",
-		recordID,
-		personnelID,
-		campus,
-		trainingCode,
-		completedAt,
-		issuedBy,
-	)
+	return course, nil
+}
+
+func (c *PersonnelClient) ListCourses(ctx context.Context) ([]*domain.Course, error) {
+	result, err := c.contract.EvaluateWithContext(ctx, "CatalogueContract:ListCourses")
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var courses []*domain.Course
+	if err := json.Unmarshal(result, &courses); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return courses, nil
+}
+
+// MigrateState migrates one page, pass the returned bookmark back in until the result is done
+func (c *PersonnelClient) MigrateState(ctx context.Context, fromVersion, pageSize int, bookmark string) (*domain.MigrationResult, error) {
+	if pageSize < 1 {
+		return nil, fmt.Errorf("pageSize must be positive")
+	}
+
+	result, err := c.contract.SubmitWithContext(ctx, "AdminContract:MigrateState", client.WithArguments(strconv.Itoa(fromVersion), strconv.Itoa(pageSize), bookmark))
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit transaction: %w", err)
 	}
 
-	var training *domain.Training
-	if err := json.Unmarshal(result, &training); err != nil {
+	var migration *domain.MigrationResult
+	if err := json.Unmarshal(result, &migration); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
 	}
 
-	return training, nil
+	return migration, nil
+}
+
+// VerifyIndexes checks one page, pass the returned bookmark back in until the report is done
+func (c *PersonnelClient) VerifyIndexes(ctx context.Context, pageSize int, bookmark string) (*domain.IndexReport, error) {
+	if pageSize < 1 {
+		return nil, fmt.Errorf("pageSize must be positive")
+	}
+
+	result, err := c.contract.EvaluateWithContext(ctx, "AdminContract:VerifyIndexes", client.WithArguments(strconv.Itoa(pageSize), bookmark))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var report *domain.IndexReport
+	if err := json.Unmarshal(result, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return report, nil
+}
+
+// RepairIndexes repairs one page, pass the returned bookmark back in until the result is done
+func (c *PersonnelClient) RepairIndexes(ctx context.Context, pageSize int, bookmark string) (*domain.IndexRepairResult, error) {
+	if pageSize < 1 {
+		return nil, fmt.Errorf("pageSize must be positive")
+	}
+
+	result, err := c.contract.SubmitWithContext(ctx, "AdminContract:RepairIndexes", client.WithArguments(strconv.Itoa(pageSize), bookmark))
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit transaction: %w", err)
+	}
+
+	var repair *domain.IndexRepairResult
+	if err := json.Unmarshal(result, &repair); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return repair, nil
+}
+
+// EnrollCadetsBatch enrols all of the cadets in one transaction, or none of them if any is rejected
+func (c *PersonnelClient) EnrollCadetsBatch(ctx context.Context, cadets []domain.CadetEnrolment) (*domain.BatchResult, error) {
+	if len(cadets) == 0 {
+		return nil, fmt.Errorf("at least one cadet is required")
+	}
+
+	cadetsJSON, err := json.Marshal(cadets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cadets: %w", err)
+	}
+
+	result, err := c.contract.SubmitWithContext(ctx, "PersonnelContract:EnrollCadetsBatch", client.WithArguments(string(cadetsJSON)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit transaction: %w", err)
+	}
+
+	var batch *domain.BatchResult
+	if err := json.Unmarshal(result, &batch); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return batch, nil
+}
+
+// ValidateEnrolment applies the same rules as PersonnelContract:EnrollCadet, so bad entries are
+// caught before anything is submitted
+func ValidateEnrolment(cadet domain.CadetEnrolment) error {
+	if cadet.PersonnelID == "" {
+		return ErrInvalidPersonnelID
+	}
+	if cadet.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if cadet.Campus == "" {
+		return fmt.Errorf("campus is required")
+	}
+	return nil
+}
+
+// CompleteTrainingBatch records each completion independently, the result reports which were written
+func (c *PersonnelClient) CompleteTrainingBatch(ctx context.Context, completions []domain.TrainingCompletion) (*domain.BatchResult, error) {
+	if len(completions) == 0 {
+		return nil, fmt.Errorf("at least one training completion is required")
+	}
+
+	completionsJSON, err := json.Marshal(completions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal training completions: %w", err)
+	}
+
+	result, err := c.contract.SubmitWithContext(ctx, "TrainingContract:CompleteTrainingBatch", client.WithArguments(string(completionsJSON)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit transaction: %w", err)
+	}
+
+	var batch *domain.BatchResult
+	if err := json.Unmarshal(result, &batch); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return batch, nil
+}
+
+// ValidateTraining applies the parameter rules of TrainingContract:CompleteTraining, the ledger
+// checks such as the personnel's campus can only be made by the chaincode
+func ValidateTraining(completion domain.TrainingCompletion) error {
+	// Parameter validation
+	if completion.RecordID == "" {
+		return fmt.Errorf("recordID is required")
+	}
+	if completion.PersonnelID == "" {
+		return ErrInvalidPersonnelID
+	}
+	if completion.Campus == "" {
+		return fmt.Errorf("campus is required")
+	}
+	if completion.TrainingCode == "" {
+		return fmt.Errorf("trainingCode is required")
+	}
+	if completion.CompletedAt == "" {
+		return fmt.Errorf("completedAt is required")
+	}
+	if completion.IssuedBy == "" {
+		return fmt.Errorf("issuedBy is required")
+	}
+
+	// Check for valid completedAt format (ISO 8601)
+	if _, err := time.Parse(time.RFC3339, completion.CompletedAt); err != nil {
+		return fmt.Errorf("completedAt must be in ISO 8601 / RFC3339 format: %w", err)
+	}
+
+	return nil
+}
+
+// GetMetadata returns the contractapi metadata describing every contract, transaction and schema
+func (c *PersonnelClient) GetMetadata(ctx context.Context) ([]byte, error) {
+	result, err := c.contract.EvaluateWithContext(ctx, "org.hyperledger.fabric:GetMetadata")
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+func (c *PersonnelClient) GetRoster(ctx context.Context, campus string) ([]*domain.Personnel, error) {
+	if campus == "" {
+		return nil, fmt.Errorf("campus is required")
+	}
+
+	result, err := c.contract.EvaluateWithContext(ctx, "ReportContract:GetRoster", client.WithArguments(campus))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var personnel []*domain.Personnel
+	if err := json.Unmarshal(result, &personnel); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return personnel, nil
+}
+
+// GetCompletionStats counts completed training, any of the filters may be empty. from and to are RFC3339.
+func (c *PersonnelClient) GetCompletionStats(ctx context.Context, trainingCode, from, to string) (*domain.CompletionReport, error) {
+	result, err := c.contract.EvaluateWithContext(ctx, "ReportContract:GetCompletionStats", client.WithArguments(trainingCode, from, to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var report *domain.CompletionReport
+	if err := json.Unmarshal(result, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetRankDistribution covers the whole academy when campus is empty
+func (c *PersonnelClient) GetRankDistribution(ctx context.Context, campus string) (*domain.RankDistribution, error) {
+	result, err := c.contract.EvaluateWithContext(ctx, "ReportContract:GetRankDistribution", client.WithArguments(campus))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var distribution *domain.RankDistribution
+	if err := json.Unmarshal(result, &distribution); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return distribution, nil
 }
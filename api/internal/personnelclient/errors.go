@@ -0,0 +1,90 @@
+package personnelclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorKind classifies a client or contract error so servers can map it onto their own status codes
+type ErrorKind int
+
+const (
+	ErrorInternal ErrorKind = iota
+	ErrorInvalid
+	ErrorNotFound
+	ErrorConflict
+	ErrorDenied
+	ErrorUnavailable
+	ErrorTimeout
+)
+
+// chaincodeResponsePrefix precedes the chaincode's own message in gateway error details
+const chaincodeResponsePrefix = "chaincode response 500, "
+
+// Chaincode errors reach the client only as text, so they are classified by the wording the
+// contracts use. Checked in order, the first match wins.
+var errorPhrases = []struct {
+	phrase string
+	kind   ErrorKind
+}{
+	{"access denied", ErrorDenied},
+	{"does not exist", ErrorNotFound},
+	{"already exists", ErrorConflict},
+	{"already completed", ErrorConflict},
+	{"cannot complete training", ErrorConflict},
+	{"is not enrolled in campus", ErrorConflict},
+	{"is required", ErrorInvalid},
+	{"must be", ErrorInvalid},
+	{"invalid", ErrorInvalid},
+	{"not found in contract", ErrorInvalid},
+}
+
+// Classify returns the kind of err, as returned by any PersonnelClient method
+func Classify(err error) ErrorKind {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTimeout
+	}
+
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.Unavailable:
+			return ErrorUnavailable
+		case codes.DeadlineExceeded:
+			return ErrorTimeout
+		case codes.PermissionDenied, codes.Unauthenticated:
+			return ErrorDenied
+		}
+	}
+
+	message := strings.ToLower(ContractMessage(err))
+	for _, candidate := range errorPhrases {
+		if strings.Contains(message, candidate.phrase) {
+			return candidate.kind
+		}
+	}
+
+	return ErrorInternal
+}
+
+// ContractMessage returns the chaincode's own message from a gateway error, without the gateway and
+// endorser wrapping, or the error's message if it did not come from the chaincode
+func ContractMessage(err error) string {
+	if st, ok := status.FromError(err); ok {
+		for _, detail := range st.Details() {
+			if detail, ok := detail.(*gateway.ErrorDetail); ok && detail.GetMessage() != "" {
+				message := detail.GetMessage()
+				if index := strings.Index(message, chaincodeResponsePrefix); index != -1 {
+					message = message[index+len(chaincodeResponsePrefix):]
+				}
+				return message
+			}
+		}
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,189 @@
+package personnelclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// ErrEventStreamEnded is returned by event consumers when the peer closes the stream, listening
+// again from their checkpoint carries on where they stopped
+var ErrEventStreamEnded = errors.New("chaincode event stream ended")
+
+// Event is a decoded chaincode event. Batch events are split so each Event carries one record,
+// Name is then the single-record event name.
+type Event struct {
+	BlockNumber   uint64
+	TransactionID string
+	Name          string
+
+	// Personnel is set for EventCadetEnrolled, Training for EventTrainingCompleted
+	Personnel *domain.Personnel
+	Training  *domain.Training
+}
+
+// NetworkSource provides the channel over the current peer connection, as fabricgateway.Gateway does
+type NetworkSource interface {
+	GetNetwork(ctx context.Context) (*client.Network, error)
+	// Reconnect replaces the connection serving stale, unless that has already been done
+	Reconnect(ctx context.Context, stale *client.Network) error
+}
+
+// EventWatcher listens for the chaincode's events on the channel. Each listen uses the current
+// connection, and a stream the peer ends replaces it so listening again starts afresh.
+type EventWatcher struct {
+	networks      NetworkSource
+	chaincodeName string
+}
+
+func NewEventWatcher(networks NetworkSource, chaincodeName string) *EventWatcher {
+	return &EventWatcher{
+		networks:      networks,
+		chaincodeName: chaincodeName,
+	}
+}
+
+// Watch streams events until ctx is cancelled or the connection fails, closing the channel either way.
+// With a nil startBlock only events committed from now on are received.
+func (w *EventWatcher) Watch(ctx context.Context, startBlock *uint64) (<-chan *Event, error) {
+	var options []client.ChaincodeEventsOption
+	if startBlock != nil {
+		options = append(options, client.WithStartBlock(*startBlock))
+	}
+
+	transactions, err := w.listen(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan *Event)
+	go func() {
+		defer close(events)
+
+		for transaction := range transactions {
+			for _, event := range transaction {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// WatchFrom streams the events of each transaction together, so a consumer can apply and checkpoint
+// a batch event as one unit. Listening resumes after the checkpoint, or from startBlock when the
+// checkpoint is still at its zero value.
+func (w *EventWatcher) WatchFrom(ctx context.Context, checkpoint client.Checkpoint, startBlock uint64) (<-chan []*Event, error) {
+	return w.listen(ctx, client.WithStartBlock(startBlock), client.WithCheckpoint(checkpoint))
+}
+
+func (w *EventWatcher) listen(ctx context.Context, options ...client.ChaincodeEventsOption) (<-chan []*Event, error) {
+	network, err := w.networks.GetNetwork(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	chaincodeEvents, err := network.ChaincodeEvents(ctx, w.chaincodeName, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start chaincode event listening: %w", err)
+	}
+
+	transactions := make(chan []*Event)
+	go func() {
+		defer close(transactions)
+
+		// The stream ends without saying why, an ending not asked for is treated as a lost peer
+		defer func() {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := w.networks.Reconnect(ctx, network); err != nil {
+				log.Printf("failed to reconnect after the event stream ended: %v", err)
+			}
+		}()
+
+		for chaincodeEvent := range chaincodeEvents {
+			decoded, err := decodeEvent(chaincodeEvent)
+			if err != nil {
+				// One malformed event should not end the stream for every watcher
+				log.Printf("skipping event %s in transaction %s: %v", chaincodeEvent.EventName, chaincodeEvent.TransactionID, err)
+				continue
+			}
+
+			select {
+			case transactions <- decoded:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return transactions, nil
+}
+
+func decodeEvent(chaincodeEvent *client.ChaincodeEvent) ([]*Event, error) {
+	newEvent := func(name string) *Event {
+		return &Event{
+			BlockNumber:   chaincodeEvent.BlockNumber,
+			TransactionID: chaincodeEvent.TransactionID,
+			Name:          name,
+		}
+	}
+
+	switch chaincodeEvent.EventName {
+	case domain.EventCadetEnrolled, domain.EventCadetsEnrolled:
+		var personnel []*domain.Personnel
+		if err := unmarshalOneOrMany(chaincodeEvent.Payload, &personnel); err != nil {
+			return nil, err
+		}
+
+		events := make([]*Event, len(personnel))
+		for i := range personnel {
+			events[i] = newEvent(domain.EventCadetEnrolled)
+			events[i].Personnel = personnel[i]
+		}
+		return events, nil
+
+	case domain.EventTrainingCompleted, domain.EventTrainingsCompleted:
+		var training []*domain.Training
+		if err := unmarshalOneOrMany(chaincodeEvent.Payload, &training); err != nil {
+			return nil, err
+		}
+
+		events := make([]*Event, len(training))
+		for i := range training {
+			events[i] = newEvent(domain.EventTrainingCompleted)
+			events[i].Training = training[i]
+		}
+		return events, nil
+	}
+
+	return nil, fmt.Errorf("unknown event")
+}
+
+// unmarshalOneOrMany decodes either a single JSON object or an array of them into target
+func unmarshalOneOrMany[T any](payload []byte, target *[]*T) error {
+	if len(payload) > 0 && payload[0] == '[' {
+		if err := json.Unmarshal(payload, target); err != nil {
+			return fmt.Errorf("failed to unmarshal event payload: %w", err)
+		}
+		return nil
+	}
+
+	var single *T
+	if err := json.Unmarshal(payload, &single); err != nil {
+		return fmt.Errorf("failed to unmarshal event payload: %w", err)
+	}
+	*target = []*T{single}
+
+	return nil
+}
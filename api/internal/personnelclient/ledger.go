@@ -0,0 +1,57 @@
+package personnelclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"google.golang.org/protobuf/proto"
+)
+
+// Ledger reads blocks and chain information through the peer's qscc system chaincode
+type Ledger struct {
+	contract    *client.Contract
+	channelName string
+}
+
+func NewLedger(network *client.Network) *Ledger {
+	return &Ledger{
+		contract:    network.GetContract("qscc"),
+		channelName: network.Name(),
+	}
+}
+
+// Height returns the number of blocks on the channel, the last block is Height - 1
+func (l *Ledger) Height(ctx context.Context) (uint64, error) {
+	result, err := l.contract.EvaluateWithContext(ctx, "GetChainInfo", client.WithArguments(l.channelName))
+	if err != nil {
+		return 0, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	info := &common.BlockchainInfo{}
+	if err := proto.Unmarshal(result, info); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal chain info: %w", err)
+	}
+
+	return info.GetHeight(), nil
+}
+
+// BlockByTransactionID returns the block holding the transaction
+func (l *Ledger) BlockByTransactionID(ctx context.Context, transactionID string) (*common.Block, error) {
+	if transactionID == "" {
+		return nil, fmt.Errorf("transactionID is required")
+	}
+
+	result, err := l.contract.EvaluateWithContext(ctx, "GetBlockByTxID", client.WithArguments(l.channelName, transactionID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	block := &common.Block{}
+	if err := proto.Unmarshal(result, block); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block: %w", err)
+	}
+
+	return block, nil
+}
@@ -0,0 +1,5 @@
+// Package personnelpb holds the generated code for the PersonnelService protobuf definitions.
+// Regenerating needs protoc with protoc-gen-go and protoc-gen-go-grpc on the PATH.
+package personnelpb
+
+//go:generate protoc -I ../../proto --go_out=module=github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelpb:. --go-grpc_out=module=github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelpb:. starfleet/personnel/v1/personnel_service.proto
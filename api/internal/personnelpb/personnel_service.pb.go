@@ -0,0 +1,737 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: starfleet/personnel/v1/personnel_service.proto
+
+package personnelpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Personnel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PersonnelId   string                 `protobuf:"bytes,1,opt,name=personnel_id,json=personnelId,proto3" json:"personnel_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Rank          string                 `protobuf:"bytes,3,opt,name=rank,proto3" json:"rank,omitempty"`
+	Campus        string                 `protobuf:"bytes,4,opt,name=campus,proto3" json:"campus,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Personnel) Reset() {
+	*x = Personnel{}
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Personnel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Personnel) ProtoMessage() {}
+
+func (x *Personnel) ProtoReflect() protoreflect.Message {
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Personnel.ProtoReflect.Descriptor instead.
+func (*Personnel) Descriptor() ([]byte, []int) {
+	return file_starfleet_personnel_v1_personnel_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Personnel) GetPersonnelId() string {
+	if x != nil {
+		return x.PersonnelId
+	}
+	return ""
+}
+
+func (x *Personnel) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Personnel) GetRank() string {
+	if x != nil {
+		return x.Rank
+	}
+	return ""
+}
+
+func (x *Personnel) GetCampus() string {
+	if x != nil {
+		return x.Campus
+	}
+	return ""
+}
+
+func (x *Personnel) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type Training struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	RecordId     string                 `protobuf:"bytes,1,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	PersonnelId  string                 `protobuf:"bytes,2,opt,name=personnel_id,json=personnelId,proto3" json:"personnel_id,omitempty"`
+	Campus       string                 `protobuf:"bytes,3,opt,name=campus,proto3" json:"campus,omitempty"`
+	TrainingCode string                 `protobuf:"bytes,4,opt,name=training_code,json=trainingCode,proto3" json:"training_code,omitempty"`
+	// RFC 3339 timestamp
+	CompletedAt   string `protobuf:"bytes,5,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	IssuedBy      string `protobuf:"bytes,6,opt,name=issued_by,json=issuedBy,proto3" json:"issued_by,omitempty"`
+	Status        string `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Training) Reset() {
+	*x = Training{}
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Training) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Training) ProtoMessage() {}
+
+func (x *Training) ProtoReflect() protoreflect.Message {
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Training.ProtoReflect.Descriptor instead.
+func (*Training) Descriptor() ([]byte, []int) {
+	return file_starfleet_personnel_v1_personnel_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Training) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *Training) GetPersonnelId() string {
+	if x != nil {
+		return x.PersonnelId
+	}
+	return ""
+}
+
+func (x *Training) GetCampus() string {
+	if x != nil {
+		return x.Campus
+	}
+	return ""
+}
+
+func (x *Training) GetTrainingCode() string {
+	if x != nil {
+		return x.TrainingCode
+	}
+	return ""
+}
+
+func (x *Training) GetCompletedAt() string {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return ""
+}
+
+func (x *Training) GetIssuedBy() string {
+	if x != nil {
+		return x.IssuedBy
+	}
+	return ""
+}
+
+func (x *Training) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type GetPersonnelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PersonnelId   string                 `protobuf:"bytes,1,opt,name=personnel_id,json=personnelId,proto3" json:"personnel_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPersonnelRequest) Reset() {
+	*x = GetPersonnelRequest{}
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPersonnelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPersonnelRequest) ProtoMessage() {}
+
+func (x *GetPersonnelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPersonnelRequest.ProtoReflect.Descriptor instead.
+func (*GetPersonnelRequest) Descriptor() ([]byte, []int) {
+	return file_starfleet_personnel_v1_personnel_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetPersonnelRequest) GetPersonnelId() string {
+	if x != nil {
+		return x.PersonnelId
+	}
+	return ""
+}
+
+type EnrollCadetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PersonnelId   string                 `protobuf:"bytes,1,opt,name=personnel_id,json=personnelId,proto3" json:"personnel_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Campus        string                 `protobuf:"bytes,3,opt,name=campus,proto3" json:"campus,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnrollCadetRequest) Reset() {
+	*x = EnrollCadetRequest{}
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnrollCadetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnrollCadetRequest) ProtoMessage() {}
+
+func (x *EnrollCadetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnrollCadetRequest.ProtoReflect.Descriptor instead.
+func (*EnrollCadetRequest) Descriptor() ([]byte, []int) {
+	return file_starfleet_personnel_v1_personnel_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EnrollCadetRequest) GetPersonnelId() string {
+	if x != nil {
+		return x.PersonnelId
+	}
+	return ""
+}
+
+func (x *EnrollCadetRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *EnrollCadetRequest) GetCampus() string {
+	if x != nil {
+		return x.Campus
+	}
+	return ""
+}
+
+type CompleteTrainingRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	RecordId     string                 `protobuf:"bytes,1,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	PersonnelId  string                 `protobuf:"bytes,2,opt,name=personnel_id,json=personnelId,proto3" json:"personnel_id,omitempty"`
+	Campus       string                 `protobuf:"bytes,3,opt,name=campus,proto3" json:"campus,omitempty"`
+	TrainingCode string                 `protobuf:"bytes,4,opt,name=training_code,json=trainingCode,proto3" json:"training_code,omitempty"`
+	// RFC 3339 timestamp
+	CompletedAt   string `protobuf:"bytes,5,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	IssuedBy      string `protobuf:"bytes,6,opt,name=issued_by,json=issuedBy,proto3" json:"issued_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteTrainingRequest) Reset() {
+	*x = CompleteTrainingRequest{}
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteTrainingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteTrainingRequest) ProtoMessage() {}
+
+func (x *CompleteTrainingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteTrainingRequest.ProtoReflect.Descriptor instead.
+func (*CompleteTrainingRequest) Descriptor() ([]byte, []int) {
+	return file_starfleet_personnel_v1_personnel_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CompleteTrainingRequest) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *CompleteTrainingRequest) GetPersonnelId() string {
+	if x != nil {
+		return x.PersonnelId
+	}
+	return ""
+}
+
+func (x *CompleteTrainingRequest) GetCampus() string {
+	if x != nil {
+		return x.Campus
+	}
+	return ""
+}
+
+func (x *CompleteTrainingRequest) GetTrainingCode() string {
+	if x != nil {
+		return x.TrainingCode
+	}
+	return ""
+}
+
+func (x *CompleteTrainingRequest) GetCompletedAt() string {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return ""
+}
+
+func (x *CompleteTrainingRequest) GetIssuedBy() string {
+	if x != nil {
+		return x.IssuedBy
+	}
+	return ""
+}
+
+type WatchEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Replays events from this block, to resume after the block_number of the last event received.
+	// When unset only events committed after the call are streamed.
+	StartBlock    *uint64 `protobuf:"varint,1,opt,name=start_block,json=startBlock,proto3,oneof" json:"start_block,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_starfleet_personnel_v1_personnel_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchEventsRequest) GetStartBlock() uint64 {
+	if x != nil && x.StartBlock != nil {
+		return *x.StartBlock
+	}
+	return 0
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlockNumber   uint64                 `protobuf:"varint,1,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	TransactionId string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Event_CadetEnrolled
+	//	*Event_TrainingCompleted
+	Payload       isEvent_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_starfleet_personnel_v1_personnel_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Event) GetBlockNumber() uint64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *Event) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *Event) GetPayload() isEvent_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Event) GetCadetEnrolled() *CadetEnrolled {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_CadetEnrolled); ok {
+			return x.CadetEnrolled
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetTrainingCompleted() *TrainingCompleted {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_TrainingCompleted); ok {
+			return x.TrainingCompleted
+		}
+	}
+	return nil
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_CadetEnrolled struct {
+	CadetEnrolled *CadetEnrolled `protobuf:"bytes,3,opt,name=cadet_enrolled,json=cadetEnrolled,proto3,oneof"`
+}
+
+type Event_TrainingCompleted struct {
+	TrainingCompleted *TrainingCompleted `protobuf:"bytes,4,opt,name=training_completed,json=trainingCompleted,proto3,oneof"`
+}
+
+func (*Event_CadetEnrolled) isEvent_Payload() {}
+
+func (*Event_TrainingCompleted) isEvent_Payload() {}
+
+type CadetEnrolled struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Personnel     *Personnel             `protobuf:"bytes,1,opt,name=personnel,proto3" json:"personnel,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CadetEnrolled) Reset() {
+	*x = CadetEnrolled{}
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CadetEnrolled) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CadetEnrolled) ProtoMessage() {}
+
+func (x *CadetEnrolled) ProtoReflect() protoreflect.Message {
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CadetEnrolled.ProtoReflect.Descriptor instead.
+func (*CadetEnrolled) Descriptor() ([]byte, []int) {
+	return file_starfleet_personnel_v1_personnel_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CadetEnrolled) GetPersonnel() *Personnel {
+	if x != nil {
+		return x.Personnel
+	}
+	return nil
+}
+
+type TrainingCompleted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Training      *Training              `protobuf:"bytes,1,opt,name=training,proto3" json:"training,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrainingCompleted) Reset() {
+	*x = TrainingCompleted{}
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrainingCompleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrainingCompleted) ProtoMessage() {}
+
+func (x *TrainingCompleted) ProtoReflect() protoreflect.Message {
+	mi := &file_starfleet_personnel_v1_personnel_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrainingCompleted.ProtoReflect.Descriptor instead.
+func (*TrainingCompleted) Descriptor() ([]byte, []int) {
+	return file_starfleet_personnel_v1_personnel_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TrainingCompleted) GetTraining() *Training {
+	if x != nil {
+		return x.Training
+	}
+	return nil
+}
+
+var File_starfleet_personnel_v1_personnel_service_proto protoreflect.FileDescriptor
+
+const file_starfleet_personnel_v1_personnel_service_proto_rawDesc = "" +
+	"\n" +
+	".starfleet/personnel/v1/personnel_service.proto\x12\x16starfleet.personnel.v1\"\x86\x01\n" +
+	"\tPersonnel\x12!\n" +
+	"\fpersonnel_id\x18\x01 \x01(\tR\vpersonnelId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04rank\x18\x03 \x01(\tR\x04rank\x12\x16\n" +
+	"\x06campus\x18\x04 \x01(\tR\x06campus\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\"\xdf\x01\n" +
+	"\bTraining\x12\x1b\n" +
+	"\trecord_id\x18\x01 \x01(\tR\brecordId\x12!\n" +
+	"\fpersonnel_id\x18\x02 \x01(\tR\vpersonnelId\x12\x16\n" +
+	"\x06campus\x18\x03 \x01(\tR\x06campus\x12#\n" +
+	"\rtraining_code\x18\x04 \x01(\tR\ftrainingCode\x12!\n" +
+	"\fcompleted_at\x18\x05 \x01(\tR\vcompletedAt\x12\x1b\n" +
+	"\tissued_by\x18\x06 \x01(\tR\bissuedBy\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\"8\n" +
+	"\x13GetPersonnelRequest\x12!\n" +
+	"\fpersonnel_id\x18\x01 \x01(\tR\vpersonnelId\"c\n" +
+	"\x12EnrollCadetRequest\x12!\n" +
+	"\fpersonnel_id\x18\x01 \x01(\tR\vpersonnelId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06campus\x18\x03 \x01(\tR\x06campus\"\xd6\x01\n" +
+	"\x17CompleteTrainingRequest\x12\x1b\n" +
+	"\trecord_id\x18\x01 \x01(\tR\brecordId\x12!\n" +
+	"\fpersonnel_id\x18\x02 \x01(\tR\vpersonnelId\x12\x16\n" +
+	"\x06campus\x18\x03 \x01(\tR\x06campus\x12#\n" +
+	"\rtraining_code\x18\x04 \x01(\tR\ftrainingCode\x12!\n" +
+	"\fcompleted_at\x18\x05 \x01(\tR\vcompletedAt\x12\x1b\n" +
+	"\tissued_by\x18\x06 \x01(\tR\bissuedBy\"J\n" +
+	"\x12WatchEventsRequest\x12$\n" +
+	"\vstart_block\x18\x01 \x01(\x04H\x00R\n" +
+	"startBlock\x88\x01\x01B\x0e\n" +
+	"\f_start_block\"\x88\x02\n" +
+	"\x05Event\x12!\n" +
+	"\fblock_number\x18\x01 \x01(\x04R\vblockNumber\x12%\n" +
+	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12N\n" +
+	"\x0ecadet_enrolled\x18\x03 \x01(\v2%.starfleet.personnel.v1.CadetEnrolledH\x00R\rcadetEnrolled\x12Z\n" +
+	"\x12training_completed\x18\x04 \x01(\v2).starfleet.personnel.v1.TrainingCompletedH\x00R\x11trainingCompletedB\t\n" +
+	"\apayload\"P\n" +
+	"\rCadetEnrolled\x12?\n" +
+	"\tpersonnel\x18\x01 \x01(\v2!.starfleet.personnel.v1.PersonnelR\tpersonnel\"Q\n" +
+	"\x11TrainingCompleted\x12<\n" +
+	"\btraining\x18\x01 \x01(\v2 .starfleet.personnel.v1.TrainingR\btraining2\x93\x03\n" +
+	"\x10PersonnelService\x12^\n" +
+	"\fGetPersonnel\x12+.starfleet.personnel.v1.GetPersonnelRequest\x1a!.starfleet.personnel.v1.Personnel\x12\\\n" +
+	"\vEnrollCadet\x12*.starfleet.personnel.v1.EnrollCadetRequest\x1a!.starfleet.personnel.v1.Personnel\x12e\n" +
+	"\x10CompleteTraining\x12/.starfleet.personnel.v1.CompleteTrainingRequest\x1a .starfleet.personnel.v1.Training\x12Z\n" +
+	"\vWatchEvents\x12*.starfleet.personnel.v1.WatchEventsRequest\x1a\x1d.starfleet.personnel.v1.Event0\x01BaZ_github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelpb;personnelpbb\x06proto3"
+
+var (
+	file_starfleet_personnel_v1_personnel_service_proto_rawDescOnce sync.Once
+	file_starfleet_personnel_v1_personnel_service_proto_rawDescData []byte
+)
+
+func file_starfleet_personnel_v1_personnel_service_proto_rawDescGZIP() []byte {
+	file_starfleet_personnel_v1_personnel_service_proto_rawDescOnce.Do(func() {
+		file_starfleet_personnel_v1_personnel_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_starfleet_personnel_v1_personnel_service_proto_rawDesc), len(file_starfleet_personnel_v1_personnel_service_proto_rawDesc)))
+	})
+	return file_starfleet_personnel_v1_personnel_service_proto_rawDescData
+}
+
+var file_starfleet_personnel_v1_personnel_service_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_starfleet_personnel_v1_personnel_service_proto_goTypes = []any{
+	(*Personnel)(nil),               // 0: starfleet.personnel.v1.Personnel
+	(*Training)(nil),                // 1: starfleet.personnel.v1.Training
+	(*GetPersonnelRequest)(nil),     // 2: starfleet.personnel.v1.GetPersonnelRequest
+	(*EnrollCadetRequest)(nil),      // 3: starfleet.personnel.v1.EnrollCadetRequest
+	(*CompleteTrainingRequest)(nil), // 4: starfleet.personnel.v1.CompleteTrainingRequest
+	(*WatchEventsRequest)(nil),      // 5: starfleet.personnel.v1.WatchEventsRequest
+	(*Event)(nil),                   // 6: starfleet.personnel.v1.Event
+	(*CadetEnrolled)(nil),           // 7: starfleet.personnel.v1.CadetEnrolled
+	(*TrainingCompleted)(nil),       // 8: starfleet.personnel.v1.TrainingCompleted
+}
+var file_starfleet_personnel_v1_personnel_service_proto_depIdxs = []int32{
+	7, // 0: starfleet.personnel.v1.Event.cadet_enrolled:type_name -> starfleet.personnel.v1.CadetEnrolled
+	8, // 1: starfleet.personnel.v1.Event.training_completed:type_name -> starfleet.personnel.v1.TrainingCompleted
+	0, // 2: starfleet.personnel.v1.CadetEnrolled.personnel:type_name -> starfleet.personnel.v1.Personnel
+	1, // 3: starfleet.personnel.v1.TrainingCompleted.training:type_name -> starfleet.personnel.v1.Training
+	2, // 4: starfleet.personnel.v1.PersonnelService.GetPersonnel:input_type -> starfleet.personnel.v1.GetPersonnelRequest
+	3, // 5: starfleet.personnel.v1.PersonnelService.EnrollCadet:input_type -> starfleet.personnel.v1.EnrollCadetRequest
+	4, // 6: starfleet.personnel.v1.PersonnelService.CompleteTraining:input_type -> starfleet.personnel.v1.CompleteTrainingRequest
+	5, // 7: starfleet.personnel.v1.PersonnelService.WatchEvents:input_type -> starfleet.personnel.v1.WatchEventsRequest
+	0, // 8: starfleet.personnel.v1.PersonnelService.GetPersonnel:output_type -> starfleet.personnel.v1.Personnel
+	0, // 9: starfleet.personnel.v1.PersonnelService.EnrollCadet:output_type -> starfleet.personnel.v1.Personnel
+	1, // 10: starfleet.personnel.v1.PersonnelService.CompleteTraining:output_type -> starfleet.personnel.v1.Training
+	6, // 11: starfleet.personnel.v1.PersonnelService.WatchEvents:output_type -> starfleet.personnel.v1.Event
+	8, // [8:12] is the sub-list for method output_type
+	4, // [4:8] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_starfleet_personnel_v1_personnel_service_proto_init() }
+func file_starfleet_personnel_v1_personnel_service_proto_init() {
+	if File_starfleet_personnel_v1_personnel_service_proto != nil {
+		return
+	}
+	file_starfleet_personnel_v1_personnel_service_proto_msgTypes[5].OneofWrappers = []any{}
+	file_starfleet_personnel_v1_personnel_service_proto_msgTypes[6].OneofWrappers = []any{
+		(*Event_CadetEnrolled)(nil),
+		(*Event_TrainingCompleted)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_starfleet_personnel_v1_personnel_service_proto_rawDesc), len(file_starfleet_personnel_v1_personnel_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_starfleet_personnel_v1_personnel_service_proto_goTypes,
+		DependencyIndexes: file_starfleet_personnel_v1_personnel_service_proto_depIdxs,
+		MessageInfos:      file_starfleet_personnel_v1_personnel_service_proto_msgTypes,
+	}.Build()
+	File_starfleet_personnel_v1_personnel_service_proto = out.File
+	file_starfleet_personnel_v1_personnel_service_proto_goTypes = nil
+	file_starfleet_personnel_v1_personnel_service_proto_depIdxs = nil
+}
@@ -0,0 +1,247 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: starfleet/personnel/v1/personnel_service.proto
+
+package personnelpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PersonnelService_GetPersonnel_FullMethodName     = "/starfleet.personnel.v1.PersonnelService/GetPersonnel"
+	PersonnelService_EnrollCadet_FullMethodName      = "/starfleet.personnel.v1.PersonnelService/EnrollCadet"
+	PersonnelService_CompleteTraining_FullMethodName = "/starfleet.personnel.v1.PersonnelService/CompleteTraining"
+	PersonnelService_WatchEvents_FullMethodName      = "/starfleet.personnel.v1.PersonnelService/WatchEvents"
+)
+
+// PersonnelServiceClient is the client API for PersonnelService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PersonnelService exposes the personnel chaincode to internal services over gRPC
+type PersonnelServiceClient interface {
+	GetPersonnel(ctx context.Context, in *GetPersonnelRequest, opts ...grpc.CallOption) (*Personnel, error)
+	EnrollCadet(ctx context.Context, in *EnrollCadetRequest, opts ...grpc.CallOption) (*Personnel, error)
+	CompleteTraining(ctx context.Context, in *CompleteTrainingRequest, opts ...grpc.CallOption) (*Training, error)
+	// WatchEvents streams enrolments and training completions as their transactions commit. Batch
+	// transactions are streamed as one event per record.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type personnelServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPersonnelServiceClient(cc grpc.ClientConnInterface) PersonnelServiceClient {
+	return &personnelServiceClient{cc}
+}
+
+func (c *personnelServiceClient) GetPersonnel(ctx context.Context, in *GetPersonnelRequest, opts ...grpc.CallOption) (*Personnel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Personnel)
+	err := c.cc.Invoke(ctx, PersonnelService_GetPersonnel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personnelServiceClient) EnrollCadet(ctx context.Context, in *EnrollCadetRequest, opts ...grpc.CallOption) (*Personnel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Personnel)
+	err := c.cc.Invoke(ctx, PersonnelService_EnrollCadet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personnelServiceClient) CompleteTraining(ctx context.Context, in *CompleteTrainingRequest, opts ...grpc.CallOption) (*Training, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Training)
+	err := c.cc.Invoke(ctx, PersonnelService_CompleteTraining_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personnelServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PersonnelService_ServiceDesc.Streams[0], PersonnelService_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PersonnelService_WatchEventsClient = grpc.ServerStreamingClient[Event]
+
+// PersonnelServiceServer is the server API for PersonnelService service.
+// All implementations must embed UnimplementedPersonnelServiceServer
+// for forward compatibility.
+//
+// PersonnelService exposes the personnel chaincode to internal services over gRPC
+type PersonnelServiceServer interface {
+	GetPersonnel(context.Context, *GetPersonnelRequest) (*Personnel, error)
+	EnrollCadet(context.Context, *EnrollCadetRequest) (*Personnel, error)
+	CompleteTraining(context.Context, *CompleteTrainingRequest) (*Training, error)
+	// WatchEvents streams enrolments and training completions as their transactions commit. Batch
+	// transactions are streamed as one event per record.
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedPersonnelServiceServer()
+}
+
+// UnimplementedPersonnelServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPersonnelServiceServer struct{}
+
+func (UnimplementedPersonnelServiceServer) GetPersonnel(context.Context, *GetPersonnelRequest) (*Personnel, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPersonnel not implemented")
+}
+func (UnimplementedPersonnelServiceServer) EnrollCadet(context.Context, *EnrollCadetRequest) (*Personnel, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollCadet not implemented")
+}
+func (UnimplementedPersonnelServiceServer) CompleteTraining(context.Context, *CompleteTrainingRequest) (*Training, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteTraining not implemented")
+}
+func (UnimplementedPersonnelServiceServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedPersonnelServiceServer) mustEmbedUnimplementedPersonnelServiceServer() {}
+func (UnimplementedPersonnelServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafePersonnelServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PersonnelServiceServer will
+// result in compilation errors.
+type UnsafePersonnelServiceServer interface {
+	mustEmbedUnimplementedPersonnelServiceServer()
+}
+
+func RegisterPersonnelServiceServer(s grpc.ServiceRegistrar, srv PersonnelServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPersonnelServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PersonnelService_ServiceDesc, srv)
+}
+
+func _PersonnelService_GetPersonnel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPersonnelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonnelServiceServer).GetPersonnel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonnelService_GetPersonnel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonnelServiceServer).GetPersonnel(ctx, req.(*GetPersonnelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonnelService_EnrollCadet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollCadetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonnelServiceServer).EnrollCadet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonnelService_EnrollCadet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonnelServiceServer).EnrollCadet(ctx, req.(*EnrollCadetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonnelService_CompleteTraining_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteTrainingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonnelServiceServer).CompleteTraining(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonnelService_CompleteTraining_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonnelServiceServer).CompleteTraining(ctx, req.(*CompleteTrainingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonnelService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PersonnelServiceServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PersonnelService_WatchEventsServer = grpc.ServerStreamingServer[Event]
+
+// PersonnelService_ServiceDesc is the grpc.ServiceDesc for PersonnelService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PersonnelService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "starfleet.personnel.v1.PersonnelService",
+	HandlerType: (*PersonnelServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPersonnel",
+			Handler:    _PersonnelService_GetPersonnel_Handler,
+		},
+		{
+			MethodName: "EnrollCadet",
+			Handler:    _PersonnelService_EnrollCadet_Handler,
+		},
+		{
+			MethodName: "CompleteTraining",
+			Handler:    _PersonnelService_CompleteTraining_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _PersonnelService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "starfleet/personnel/v1/personnel_service.proto",
+}
@@ -0,0 +1,200 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/fabricgateway"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the config file looked for in the working directory when none is given
+const DefaultPath = "./starfleet.yaml"
+
+var ErrProfileNotFound = errors.New("profile not found")
+
+// File is a config file holding named connection profiles. JSON files are accepted as well as YAML.
+//
+//	default: engineering-dev
+//	profiles:
+//	  dev:
+//	    peerEndpoint: localhost:7051
+//	    channelName: academy
+//	  engineering-dev:
+//	    extends: dev
+//	    mspID: EngineeringMSP
+type File struct {
+	Default  string              `yaml:"default" json:"default"`
+	Profiles map[string]*Profile `yaml:"profiles" json:"profiles"`
+}
+
+// Profile holds gateway settings, any field left empty is inherited from the profile it extends
+type Profile struct {
+	Extends string `yaml:"extends" json:"extends"`
+
+	// ConnectionProfile is a Fabric common connection profile supplying the peer and TLS settings
+	ConnectionProfile string `yaml:"connectionProfile" json:"connectionProfile"`
+	Organization      string `yaml:"organization" json:"organization"`
+	Peer              string `yaml:"peer" json:"peer"`
+
+	PeerEndpoint  string `yaml:"peerEndpoint" json:"peerEndpoint"`
+	TLSCertPath   string `yaml:"tlsCertPath" json:"tlsCertPath"`
+	TLSServerName string `yaml:"tlsServerName" json:"tlsServerName"`
+
+	MSPID    string `yaml:"mspID" json:"mspID"`
+	CertPath string `yaml:"certPath" json:"certPath"`
+	KeyPath  string `yaml:"keyPath" json:"keyPath"`
+
+	// Wallet and Identity select a labelled identity in place of CertPath and KeyPath
+	Wallet   string `yaml:"wallet" json:"wallet"`
+	Identity string `yaml:"identity" json:"identity"`
+
+	ChannelName   string `yaml:"channelName" json:"channelName"`
+	ChaincodeName string `yaml:"chaincodeName" json:"chaincodeName"`
+
+	Timeouts Timeouts `yaml:"timeouts" json:"timeouts"`
+	Connect  Connect  `yaml:"connect" json:"connect"`
+}
+
+// Timeouts are durations such as "30s"
+type Timeouts struct {
+	Evaluate     string `yaml:"evaluate" json:"evaluate"`
+	Endorse      string `yaml:"endorse" json:"endorse"`
+	Submit       string `yaml:"submit" json:"submit"`
+	CommitStatus string `yaml:"commitStatus" json:"commitStatus"`
+}
+
+type Connect struct {
+	Timeout  string `yaml:"timeout" json:"timeout"`
+	Attempts int    `yaml:"attempts" json:"attempts"`
+	Backoff  string `yaml:"backoff" json:"backoff"`
+}
+
+// Load reads a YAML or JSON profiles file
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// Names lists the profiles defined in the file
+func (f *File) Names() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply resolves the named profile, or the file default when name is empty, onto the config
+func (f *File) Apply(name string, config *fabricgateway.Config) error {
+	if name == "" {
+		name = f.Default
+	}
+	if name == "" {
+		return fmt.Errorf("no profile given and config file has no default")
+	}
+
+	chain, err := f.chain(name)
+	if err != nil {
+		return err
+	}
+
+	// Apply the most distant ancestor first so nearer profiles override it
+	for i := len(chain) - 1; i >= 0; i-- {
+		if err := chain[i].apply(config); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// chain returns the named profile followed by each profile it extends
+func (f *File) chain(name string) ([]*Profile, error) {
+	var chain []*Profile
+	seen := map[string]bool{}
+
+	for name != "" {
+		if seen[name] {
+			return nil, fmt.Errorf("profile %s extends itself", name)
+		}
+		seen[name] = true
+
+		p, ok := f.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s (available: %s)", ErrProfileNotFound, name, strings.Join(f.Names(), ", "))
+		}
+		chain = append(chain, p)
+		name = p.Extends
+	}
+
+	return chain, nil
+}
+
+func (p *Profile) apply(config *fabricgateway.Config) error {
+	setString(&config.ConnectionProfilePath, p.ConnectionProfile)
+	setString(&config.Organization, p.Organization)
+	setString(&config.PeerName, p.Peer)
+
+	setString(&config.PeerEndpoint, p.PeerEndpoint)
+	setString(&config.TLSCertPath, p.TLSCertPath)
+	setString(&config.TLSServerName, p.TLSServerName)
+
+	setString(&config.MSPID, p.MSPID)
+	setString(&config.CertPath, p.CertPath)
+	setString(&config.KeyPath, p.KeyPath)
+	setString(&config.WalletPath, p.Wallet)
+	setString(&config.Identity, p.Identity)
+
+	setString(&config.ChannelName, p.ChannelName)
+	setString(&config.ChaincodeName, p.ChaincodeName)
+
+	durations := []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"timeouts.evaluate", p.Timeouts.Evaluate, &config.EvaluateTimeout},
+		{"timeouts.endorse", p.Timeouts.Endorse, &config.EndorseTimeout},
+		{"timeouts.submit", p.Timeouts.Submit, &config.SubmitTimeout},
+		{"timeouts.commitStatus", p.Timeouts.CommitStatus, &config.CommitStatusTimeout},
+		{"connect.timeout", p.Connect.Timeout, &config.ConnectTimeout},
+		{"connect.backoff", p.Connect.Backoff, &config.ConnectBackoff},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", d.name, d.value, err)
+		}
+		*d.target = duration
+	}
+
+	if p.Connect.Attempts != 0 {
+		config.ConnectAttempts = p.Connect.Attempts
+	}
+
+	return nil
+}
+
+func setString(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
@@ -0,0 +1,63 @@
+package readmodel
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// EventSource is implemented by personnelclient.EventWatcher
+type EventSource interface {
+	WatchFrom(ctx context.Context, checkpoint client.Checkpoint, startBlock uint64) (<-chan []*personnelclient.Event, error)
+}
+
+// Projector applies chaincode events to the store as they are committed
+type Projector struct {
+	store  *Store
+	events EventSource
+}
+
+func NewProjector(store *Store, events EventSource) *Projector {
+	return &Projector{
+		store:  store,
+		events: events,
+	}
+}
+
+// Run projects events until ctx is cancelled, returning nil, or the stream ends with
+// ErrEventStreamEnded. It resumes after the stored checkpoint, startBlock only applies to an empty
+// read model.
+func (p *Projector) Run(ctx context.Context, startBlock uint64) error {
+	checkpoint, err := p.store.Checkpoint(ctx)
+	if err != nil {
+		return err
+	}
+
+	if checkpoint.BlockNumber() == 0 && checkpoint.TransactionID() == "" {
+		log.Printf("projecting events from block %d", startBlock)
+	} else {
+		log.Printf("resuming projection after transaction %s in block %d", checkpoint.TransactionID(), checkpoint.BlockNumber())
+	}
+
+	transactions, err := p.events.WatchFrom(ctx, checkpoint, startBlock)
+	if err != nil {
+		return err
+	}
+
+	for events := range transactions {
+		if err := p.store.Apply(ctx, events); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to project transaction %s: %w", events[0].TransactionID, err)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return personnelclient.ErrEventStreamEnded
+}
@@ -0,0 +1,143 @@
+package readmodel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Tables lists the queryable tables and their columns in display order
+var Tables = map[string][]string{
+	"personnel": {"personnel_id", "name", "rank", "campus", "status", "block_number", "transaction_id"},
+	"training":  {"record_id", "personnel_id", "campus", "training_code", "completed_at", "issued_by", "status", "block_number", "transaction_id"},
+	"history":   {"personnel_id", "rank", "status", "block_number", "transaction_id"},
+}
+
+// tableNames maps a queryable name onto its SQLite table
+var tableNames = map[string]string{
+	"personnel": "personnel",
+	"training":  "training",
+	"history":   "status_history",
+}
+
+// operators is ordered so two character operators are matched before their one character prefixes
+var operators = []struct {
+	token string
+	sql   string
+}{
+	{"!=", "!="},
+	{"<=", "<="},
+	{">=", ">="},
+	{"=", "="},
+	{"<", "<"},
+	{">", ">"},
+	{"~", "LIKE"},
+}
+
+// Filter is one column comparison, all filters of a query must match
+type Filter struct {
+	Column   string
+	Operator string
+	Value    string
+}
+
+// ParseFilter reads a filter such as campus=Engineering, completed_at>=2024-01-01 or name~Jean%,
+// where ~ is a LIKE match
+func ParseFilter(filter string) (Filter, error) {
+	position := strings.IndexAny(filter, "!<>=~")
+	if position < 1 {
+		return Filter{}, fmt.Errorf("filter %q must be <column><operator><value>", filter)
+	}
+
+	for _, operator := range operators {
+		if strings.HasPrefix(filter[position:], operator.token) {
+			return Filter{
+				Column:   filter[:position],
+				Operator: operator.sql,
+				Value:    filter[position+len(operator.token):],
+			}, nil
+		}
+	}
+
+	return Filter{}, fmt.Errorf("filter %q has an unknown operator", filter)
+}
+
+type Query struct {
+	Table      string
+	Filters    []Filter
+	OrderBy    string
+	Descending bool
+	// Limit of zero returns every matching row
+	Limit int
+}
+
+type Result struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Query runs q against the read model. Table and column names are checked against Tables, values
+// are always bound as parameters.
+func (s *Store) Query(ctx context.Context, q Query) (*Result, error) {
+	columns, ok := Tables[q.Table]
+	if !ok {
+		return nil, fmt.Errorf("unknown table %s", q.Table)
+	}
+
+	var where []string
+	var args []any
+	for _, filter := range q.Filters {
+		if !slices.Contains(columns, filter.Column) {
+			return nil, fmt.Errorf("unknown column %s in %s", filter.Column, q.Table)
+		}
+		where = append(where, fmt.Sprintf("%s %s ?", filter.Column, filter.Operator))
+		args = append(args, filter.Value)
+	}
+
+	statement := fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), tableNames[q.Table])
+	if len(where) > 0 {
+		statement += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	orderBy := q.OrderBy
+	if orderBy == "" {
+		orderBy = columns[0]
+	}
+	if !slices.Contains(columns, orderBy) {
+		return nil, fmt.Errorf("unknown column %s in %s", orderBy, q.Table)
+	}
+	statement += " ORDER BY " + orderBy
+	if q.Descending {
+		statement += " DESC"
+	}
+
+	if q.Limit > 0 {
+		statement += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", q.Table, err)
+	}
+	defer rows.Close()
+
+	result := &Result{Columns: columns}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("failed to read %s row: %w", q.Table, err)
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", q.Table, err)
+	}
+
+	return result, nil
+}
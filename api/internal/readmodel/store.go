@@ -0,0 +1,290 @@
+// Package readmodel keeps a local SQLite copy of personnel and training, projected from the
+// chaincode's events, for reporting queries that would be slow or impossible against world state.
+package readmodel
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/blockreplay"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	_ "modernc.org/sqlite"
+)
+
+// DefaultPath is the database used when none is given
+const DefaultPath = "./readmodel.db"
+
+const schema = `
+CREATE TABLE IF NOT EXISTS personnel (
+	personnel_id   TEXT PRIMARY KEY,
+	name           TEXT NOT NULL,
+	rank           TEXT NOT NULL,
+	campus         TEXT NOT NULL,
+	status         TEXT NOT NULL,
+	block_number   INTEGER NOT NULL,
+	transaction_id TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS training (
+	record_id      TEXT PRIMARY KEY,
+	personnel_id   TEXT NOT NULL,
+	campus         TEXT NOT NULL,
+	training_code  TEXT NOT NULL,
+	completed_at   TEXT NOT NULL,
+	issued_by      TEXT NOT NULL,
+	status         TEXT NOT NULL,
+	block_number   INTEGER NOT NULL,
+	transaction_id TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS training_by_personnel ON training (personnel_id, completed_at);
+CREATE INDEX IF NOT EXISTS training_by_code ON training (training_code);
+
+-- One row per transaction that changed a member's rank or status
+CREATE TABLE IF NOT EXISTS status_history (
+	personnel_id   TEXT NOT NULL,
+	rank           TEXT NOT NULL,
+	status         TEXT NOT NULL,
+	block_number   INTEGER NOT NULL,
+	transaction_id TEXT NOT NULL,
+	PRIMARY KEY (personnel_id, transaction_id)
+);
+
+CREATE TABLE IF NOT EXISTS checkpoint (
+	id             INTEGER PRIMARY KEY CHECK (id = 1),
+	block_number   INTEGER NOT NULL,
+	transaction_id TEXT NOT NULL
+);
+`
+
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the database at path, creating it and its tables if needed
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read model: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create read model schema: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// OpenReadOnly opens an existing database for querying, it is never created or written to
+func OpenReadOnly(path string) (*Store, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("read model %s not found, run the project command first: %w", path, err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read model: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Checkpoint is the position of the last applied transaction, it satisfies client.Checkpoint
+type Checkpoint struct {
+	blockNumber   uint64
+	transactionID string
+}
+
+func (c Checkpoint) BlockNumber() uint64 {
+	return c.blockNumber
+}
+
+func (c Checkpoint) TransactionID() string {
+	return c.transactionID
+}
+
+// Checkpoint returns the zero Checkpoint when nothing has been applied yet
+func (s *Store) Checkpoint(ctx context.Context) (Checkpoint, error) {
+	var checkpoint Checkpoint
+
+	err := s.db.QueryRowContext(ctx, "SELECT block_number, transaction_id FROM checkpoint WHERE id = 1").
+		Scan(&checkpoint.blockNumber, &checkpoint.transactionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Checkpoint{}, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// Apply projects the events of one transaction and advances the checkpoint past it atomically,
+// so a restart never skips or half-applies a transaction. Reapplying a transaction is harmless.
+func (s *Store) Apply(ctx context.Context, events []*personnelclient.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin read model transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, event := range events {
+		switch {
+		case event.Personnel != nil:
+			err = applyPersonnel(ctx, tx, event.BlockNumber, event.TransactionID, event.Personnel)
+		case event.Training != nil:
+			err = applyTraining(ctx, tx, event.BlockNumber, event.TransactionID, event.Training)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	last := events[len(events)-1]
+	if err := writeCheckpoint(ctx, tx, last.BlockNumber, last.TransactionID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit read model transaction: %w", err)
+	}
+	return nil
+}
+
+// ApplyBlock projects the document writes of a replayed block and checkpoints the whole block, so
+// the project command carries on from the next one. Writes to other document types are ignored.
+func (s *Store) ApplyBlock(ctx context.Context, blockNumber uint64, transactions []*blockreplay.Transaction) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin read model transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, transaction := range transactions {
+		for _, write := range transaction.Writes {
+			if err := applyWrite(ctx, tx, transaction, write); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The same position a chaincode event checkpointer records after a block, the next block with no
+	// transactions yet processed
+	if err := writeCheckpoint(ctx, tx, blockNumber+1, ""); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit read model transaction: %w", err)
+	}
+	return nil
+}
+
+func applyWrite(ctx context.Context, tx *sql.Tx, transaction *blockreplay.Transaction, write blockreplay.Write) error {
+	switch write.DocType {
+	case blockreplay.DocTypePersonnel:
+		if write.IsDelete {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM personnel WHERE personnel_id = ?", write.ID); err != nil {
+				return fmt.Errorf("failed to delete personnel %s: %w", write.ID, err)
+			}
+			return nil
+		}
+
+		var personnel *domain.Personnel
+		if err := json.Unmarshal(write.Value, &personnel); err != nil {
+			return fmt.Errorf("failed to unmarshal personnel %s in transaction %s: %w", write.ID, transaction.TransactionID, err)
+		}
+		return applyPersonnel(ctx, tx, transaction.BlockNumber, transaction.TransactionID, personnel)
+
+	case blockreplay.DocTypeTraining:
+		if write.IsDelete {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM training WHERE record_id = ?", write.ID); err != nil {
+				return fmt.Errorf("failed to delete training %s: %w", write.ID, err)
+			}
+			return nil
+		}
+
+		var training *domain.Training
+		if err := json.Unmarshal(write.Value, &training); err != nil {
+			return fmt.Errorf("failed to unmarshal training %s in transaction %s: %w", write.ID, transaction.TransactionID, err)
+		}
+		return applyTraining(ctx, tx, transaction.BlockNumber, transaction.TransactionID, training)
+	}
+
+	return nil
+}
+
+func writeCheckpoint(ctx context.Context, tx *sql.Tx, blockNumber uint64, transactionID string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO checkpoint (id, block_number, transaction_id) VALUES (1, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET block_number = excluded.block_number, transaction_id = excluded.transaction_id`,
+		blockNumber, transactionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+func applyPersonnel(ctx context.Context, tx *sql.Tx, blockNumber uint64, transactionID string, personnel *domain.Personnel) error {
+	// History is only written when rank or status differ from the current row, which includes a new member
+	_, err := tx.ExecContext(ctx, `
+		INSERT OR IGNORE INTO status_history (personnel_id, rank, status, block_number, transaction_id)
+		SELECT ?1, ?2, ?3, ?4, ?5
+		WHERE NOT EXISTS (SELECT 1 FROM personnel WHERE personnel_id = ?1 AND rank = ?2 AND status = ?3)`,
+		personnel.PersonnelID, personnel.Rank, personnel.Status, blockNumber, transactionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write status history for %s: %w", personnel.PersonnelID, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO personnel (personnel_id, name, rank, campus, status, block_number, transaction_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (personnel_id) DO UPDATE SET
+			name = excluded.name,
+			rank = excluded.rank,
+			campus = excluded.campus,
+			status = excluded.status,
+			block_number = excluded.block_number,
+			transaction_id = excluded.transaction_id`,
+		personnel.PersonnelID, personnel.Name, personnel.Rank, personnel.Campus, personnel.Status, blockNumber, transactionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write personnel %s: %w", personnel.PersonnelID, err)
+	}
+
+	return nil
+}
+
+func applyTraining(ctx context.Context, tx *sql.Tx, blockNumber uint64, transactionID string, training *domain.Training) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO training (record_id, personnel_id, campus, training_code, completed_at, issued_by, status, block_number, transaction_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (record_id) DO UPDATE SET
+			personnel_id = excluded.personnel_id,
+			campus = excluded.campus,
+			training_code = excluded.training_code,
+			completed_at = excluded.completed_at,
+			issued_by = excluded.issued_by,
+			status = excluded.status,
+			block_number = excluded.block_number,
+			transaction_id = excluded.transaction_id`,
+		training.RecordID, training.PersonnelID, training.Campus, training.TrainingCode, training.CompletedAt, training.IssuedBy, training.Status, blockNumber, transactionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write training %s: %w", training.RecordID, err)
+	}
+
+	return nil
+}
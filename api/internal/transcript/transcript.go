@@ -0,0 +1,115 @@
+// Package transcript renders a domain.Transcript for people, as plain text or a standalone HTML page
+package transcript
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+)
+
+// WriteText writes the transcript as aligned plain text
+func WriteText(w io.Writer, transcript *domain.Transcript, generatedAt time.Time) error {
+	personnel := transcript.Personnel
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(table, "Starfleet Academy Transcript\n\n")
+	fmt.Fprintf(table, "Personnel ID:\t%s\n", personnel.PersonnelID)
+	fmt.Fprintf(table, "Name:\t%s\n", personnel.Name)
+	fmt.Fprintf(table, "Rank:\t%s\n", personnel.Rank)
+	fmt.Fprintf(table, "Campus:\t%s\n", personnel.Campus)
+	fmt.Fprintf(table, "Status:\t%s\n", personnel.Status)
+	fmt.Fprintf(table, "Transaction:\t%s\n", transcript.TransactionID)
+	fmt.Fprintf(table, "Generated:\t%s\n", generatedAt.UTC().Format(time.RFC3339))
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nRank and Status\n")
+	table = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "CHANGED AT\tRANK\tSTATUS\tTRANSACTION\n")
+	for _, change := range transcript.StatusChanges {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", change.ChangedAt, change.Rank, change.Status, change.TransactionID)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nTraining\n")
+	if len(transcript.Training) == 0 {
+		_, err := fmt.Fprintf(w, "  No training completed\n")
+		return err
+	}
+
+	table = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "COMPLETED AT\tCODE\tCOURSE\tCAMPUS\tISSUED BY\tSTATUS\tRECORD\tTRANSACTION\n")
+	for _, line := range transcript.Training {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", line.CompletedAt, line.TrainingCode, courseTitle(line), line.Campus, line.IssuedBy, line.Status, line.RecordID, line.TransactionID)
+	}
+	return table.Flush()
+}
+
+// WriteHTML writes the transcript as a standalone, printable HTML page
+func WriteHTML(w io.Writer, transcript *domain.Transcript, generatedAt time.Time) error {
+	return htmlTemplate.Execute(w, struct {
+		*domain.Transcript
+		GeneratedAt string
+	}{transcript, generatedAt.UTC().Format(time.RFC3339)})
+}
+
+func courseTitle(line domain.TranscriptLine) string {
+	if line.CourseTitle == "" {
+		return "(not in catalogue)"
+	}
+	return line.CourseTitle
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{"courseTitle": courseTitle}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Transcript {{.Personnel.PersonnelID}} - {{.Personnel.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #eee; }
+td.tx { font-family: monospace; font-size: 0.85em; }
+</style>
+</head>
+<body>
+<h1>Starfleet Academy Transcript</h1>
+<table>
+<tr><th>Personnel ID</th><td>{{.Personnel.PersonnelID}}</td></tr>
+<tr><th>Name</th><td>{{.Personnel.Name}}</td></tr>
+<tr><th>Rank</th><td>{{.Personnel.Rank}}</td></tr>
+<tr><th>Campus</th><td>{{.Personnel.Campus}}</td></tr>
+<tr><th>Status</th><td>{{.Personnel.Status}}</td></tr>
+<tr><th>Transaction</th><td class="tx">{{.TransactionID}}</td></tr>
+<tr><th>Generated</th><td>{{.GeneratedAt}}</td></tr>
+</table>
+<h2>Rank and Status</h2>
+<table>
+<tr><th>Changed At</th><th>Rank</th><th>Status</th><th>Transaction</th></tr>
+{{- range .StatusChanges}}
+<tr><td>{{.ChangedAt}}</td><td>{{.Rank}}</td><td>{{.Status}}</td><td class="tx">{{.TransactionID}}</td></tr>
+{{- end}}
+</table>
+<h2>Training</h2>
+{{- if .Training}}
+<table>
+<tr><th>Completed At</th><th>Code</th><th>Course</th><th>Campus</th><th>Issued By</th><th>Status</th><th>Record</th><th>Transaction</th></tr>
+{{- range .Training}}
+<tr><td>{{.CompletedAt}}</td><td>{{.TrainingCode}}</td><td>{{courseTitle .}}</td><td>{{.Campus}}</td><td>{{.IssuedBy}}</td><td>{{.Status}}</td><td>{{.RecordID}}</td><td class="tx">{{.TransactionID}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No training completed</p>
+{{- end}}
+</body>
+</html>
+`))
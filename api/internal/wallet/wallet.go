@@ -0,0 +1,215 @@
+package wallet
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// DefaultPath is the wallet directory used when none is configured
+const DefaultPath = "./wallet"
+
+const (
+	identityType    = "X.509"
+	identityVersion = 1
+	fileExtension   = ".id"
+)
+
+var (
+	ErrIdentityNotFound = errors.New("identity not found in wallet")
+	ErrIdentityExists   = errors.New("identity already exists in wallet")
+)
+
+var validLabel = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@-]*$`)
+
+// Identity is an X.509 identity stored in the same `<label>.id` JSON format as the Fabric SDK
+// file-system wallets, so wallets can be shared with other Fabric tooling.
+type Identity struct {
+	Type        string      `json:"type"`
+	Version     int         `json:"version"`
+	MSPID       string      `json:"mspId"`
+	Credentials Credentials `json:"credentials"`
+}
+
+type Credentials struct {
+	Certificate string `json:"certificate"`
+	PrivateKey  string `json:"privateKey"`
+}
+
+// Wallet is a directory of labelled identities
+type Wallet struct {
+	dir string
+}
+
+// NewX509Identity checks the certificate and private key parse before building an identity
+func NewX509Identity(mspID string, certificatePEM, privateKeyPEM []byte) (*Identity, error) {
+	if mspID == "" {
+		return nil, fmt.Errorf("mspID is required")
+	}
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	// An identity whose key does not match its certificate only fails later, at the peer
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+	publicKey, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !publicKey.Equal(certificate.PublicKey) {
+		return nil, fmt.Errorf("private key does not match the certificate's public key")
+	}
+
+	return &Identity{
+		Type:    identityType,
+		Version: identityVersion,
+		MSPID:   mspID,
+		Credentials: Credentials{
+			Certificate: string(certificatePEM),
+			PrivateKey:  string(privateKeyPEM),
+		},
+	}, nil
+}
+
+// Open returns the wallet in dir. The directory is only created when an identity is first put,
+// until then the wallet is empty.
+func Open(dir string) (*Wallet, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("wallet directory is required")
+	}
+
+	return &Wallet{
+		dir: dir,
+	}, nil
+}
+
+// List returns the labels of all identities in the wallet
+func (w *Wallet) List() ([]string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wallet directory: %w", err)
+	}
+
+	var labels []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExtension) {
+			continue
+		}
+		labels = append(labels, strings.TrimSuffix(entry.Name(), fileExtension))
+	}
+	sort.Strings(labels)
+
+	return labels, nil
+}
+
+func (w *Wallet) Get(label string) (*Identity, error) {
+	path, err := w.path(label)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrIdentityNotFound, label)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity %s: %w", label, err)
+	}
+
+	var id Identity
+	if err := json.Unmarshal(data, &id); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal identity %s: %w", label, err)
+	}
+	if id.Type != identityType {
+		return nil, fmt.Errorf("identity %s has unsupported type [%s]", label, id.Type)
+	}
+
+	return &id, nil
+}
+
+// Put stores the identity under label, failing if the label is already in use
+func (w *Wallet) Put(label string, id *Identity) error {
+	path, err := w.path(label)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(id, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal identity: %w", err)
+	}
+
+	if err := os.MkdirAll(w.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create wallet directory: %w", err)
+	}
+
+	// Written in full to a temporary file first so a failed write never leaves a corrupt identity
+	file, err := os.CreateTemp(w.dir, "."+label+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create identity file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write identity file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write identity file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write identity file: %w", err)
+	}
+
+	// Linking rather than renaming fails if the label is taken, so an identity is never silently replaced
+	err = os.Link(file.Name(), path)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%w: %s", ErrIdentityExists, label)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store identity file: %w", err)
+	}
+
+	return nil
+}
+
+func (w *Wallet) Remove(label string) error {
+	path, err := w.path(label)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrIdentityNotFound, label)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove identity %s: %w", label, err)
+	}
+
+	return nil
+}
+
+func (w *Wallet) path(label string) (string, error) {
+	if !validLabel.MatchString(label) {
+		return "", fmt.Errorf("invalid identity label [%s]", label)
+	}
+	return filepath.Join(w.dir, label+fileExtension), nil
+}
@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the config file used when none is given
+const DefaultPath = "./webhooks.yaml"
+
+// Events lists the event names a hook can subscribe to. Batch events are delivered as one of these
+// per record.
+var Events = []string{domain.EventCadetEnrolled, domain.EventTrainingCompleted}
+
+// Config is the webhooks file, YAML or JSON.
+//
+//	hooks:
+//	  - name: hr
+//	    url: https://hr.example.com/hooks/starfleet
+//	    events: [CadetEnrolled]
+//	    secretEnv: HR_WEBHOOK_SECRET
+//	retry:
+//	  attempts: 5
+//	  backoff: 1s
+//	  maxBackoff: 1m
+type Config struct {
+	Hooks []Hook `yaml:"hooks" json:"hooks"`
+	Retry Retry  `yaml:"retry" json:"retry"`
+	// Timeout bounds each delivery attempt, 10s when not set
+	Timeout string `yaml:"timeout" json:"timeout"`
+}
+
+type Hook struct {
+	// Name identifies the hook in logs and dead letters, the URL is used when not set
+	Name   string   `yaml:"name" json:"name"`
+	URL    string   `yaml:"url" json:"url"`
+	Events []string `yaml:"events" json:"events"`
+
+	// SecretEnv names the environment variable holding the signing secret, keeping it out of the
+	// file. Secret is used when it is not set.
+	SecretEnv string `yaml:"secretEnv" json:"secretEnv"`
+	Secret    string `yaml:"secret" json:"secret"`
+}
+
+// Retry is applied to each delivery, the backoff doubles after every failed attempt up to MaxBackoff
+type Retry struct {
+	Attempts   int    `yaml:"attempts" json:"attempts"`
+	Backoff    string `yaml:"backoff" json:"backoff"`
+	MaxBackoff string `yaml:"maxBackoff" json:"maxBackoff"`
+}
+
+// LoadConfig reads a YAML or JSON webhooks file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhooks file: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse webhooks file %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// hook is a Hook with its secret resolved
+type hook struct {
+	name   string
+	url    string
+	events []string
+	secret []byte
+}
+
+type settings struct {
+	hooks      []hook
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	timeout    time.Duration
+}
+
+// resolve validates the config and applies the defaults
+func (c *Config) resolve() (*settings, error) {
+	if len(c.Hooks) == 0 {
+		return nil, fmt.Errorf("no hooks configured")
+	}
+
+	resolved := &settings{
+		attempts:   5,
+		backoff:    time.Second,
+		maxBackoff: time.Minute,
+		timeout:    10 * time.Second,
+	}
+
+	if c.Retry.Attempts < 0 {
+		return nil, fmt.Errorf("retry attempts must not be negative")
+	}
+	if c.Retry.Attempts > 0 {
+		resolved.attempts = c.Retry.Attempts
+	}
+
+	durations := []struct {
+		name  string
+		value string
+		into  *time.Duration
+	}{
+		{"retry backoff", c.Retry.Backoff, &resolved.backoff},
+		{"retry maxBackoff", c.Retry.MaxBackoff, &resolved.maxBackoff},
+		{"timeout", c.Timeout, &resolved.timeout},
+	}
+	for _, duration := range durations {
+		if duration.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(duration.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", duration.name, err)
+		}
+		*duration.into = parsed
+	}
+
+	for i, configured := range c.Hooks {
+		if configured.URL == "" {
+			return nil, fmt.Errorf("hook %d has no url", i)
+		}
+
+		name := configured.Name
+		if name == "" {
+			name = configured.URL
+		}
+
+		if len(configured.Events) == 0 {
+			return nil, fmt.Errorf("hook %s subscribes to no events", name)
+		}
+		for _, event := range configured.Events {
+			if !slices.Contains(Events, event) {
+				return nil, fmt.Errorf("hook %s subscribes to unknown event %s", name, event)
+			}
+		}
+
+		secret := configured.Secret
+		if configured.SecretEnv != "" {
+			secret = os.Getenv(configured.SecretEnv)
+			if secret == "" {
+				return nil, fmt.Errorf("hook %s secret variable %s is not set", name, configured.SecretEnv)
+			}
+		}
+		if secret == "" {
+			return nil, fmt.Errorf("hook %s has no secret", name)
+		}
+
+		resolved.hooks = append(resolved.hooks, hook{
+			name:   name,
+			url:    configured.URL,
+			events: configured.Events,
+			secret: []byte(secret),
+		})
+	}
+
+	return resolved, nil
+}
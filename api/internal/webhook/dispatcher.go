@@ -0,0 +1,387 @@
+// Package webhook delivers the chaincode's events to external HTTP endpoints.
+//
+// Each delivery is a POST of a JSON Payload. Receivers verify it by computing
+// hex(HMAC-SHA256(secret, timestamp + "." + body)) and comparing it with the signature header,
+// rejecting old timestamps to stop replays. Delivery is at least once, so receivers should
+// de-duplicate on the delivery ID.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// Request headers sent with every delivery
+const (
+	HeaderEvent     = "X-Starfleet-Event"
+	HeaderDelivery  = "X-Starfleet-Delivery"
+	HeaderTimestamp = "X-Starfleet-Timestamp"
+	HeaderSignature = "X-Starfleet-Signature"
+)
+
+// Payload is the body POSTed to a hook, one record per delivery
+type Payload struct {
+	// DeliveryID is the same on every attempt and for every hook
+	DeliveryID    string            `json:"deliveryID"`
+	Event         string            `json:"event"`
+	BlockNumber   uint64            `json:"blockNumber"`
+	TransactionID string            `json:"transactionID"`
+	Personnel     *domain.Personnel `json:"personnel,omitempty"`
+	Training      *domain.Training  `json:"training,omitempty"`
+}
+
+// DeadLetter records a delivery that was given up on, one JSON object per line of the dead-letter file
+type DeadLetter struct {
+	Hook     string          `json:"hook"`
+	URL      string          `json:"url"`
+	Payload  json.RawMessage `json:"payload"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	FailedAt time.Time       `json:"failedAt"`
+}
+
+// Checkpointer is satisfied by client.FileCheckpointer
+type Checkpointer interface {
+	client.Checkpoint
+	CheckpointTransaction(blockNumber uint64, transactionID string) error
+}
+
+// EventSource is implemented by personnelclient.EventWatcher
+type EventSource interface {
+	WatchFrom(ctx context.Context, checkpoint client.Checkpoint, startBlock uint64) (<-chan []*personnelclient.Event, error)
+}
+
+// hookQueueSize bounds the deliveries waiting for each hook. A hook that is failing falls behind by
+// at most this many before it holds up reading further events, and so the other hooks.
+const hookQueueSize = 256
+
+// Dispatcher delivers events with a worker per hook, each in ledger order, so a hook that is down
+// or slow only delays its own deliveries
+type Dispatcher struct {
+	settings     *settings
+	httpClient   *http.Client
+	events       EventSource
+	checkpointer Checkpointer
+
+	deadLetterMu sync.Mutex
+	deadLetters  *os.File
+}
+
+// delivery is one payload queued for one hook
+type delivery struct {
+	payload     Payload
+	body        []byte
+	transaction *pendingTransaction
+}
+
+// pendingTransaction is closed once every hook has finished with every event of a transaction
+type pendingTransaction struct {
+	blockNumber   uint64
+	transactionID string
+	remaining     atomic.Int32
+	done          chan struct{}
+}
+
+func (t *pendingTransaction) finish() {
+	if t.remaining.Add(-1) == 0 {
+		close(t.done)
+	}
+}
+
+// NewDispatcher opens the dead-letter file for appending, Close releases it
+func NewDispatcher(config *Config, events EventSource, checkpointer Checkpointer, deadLetterPath string) (*Dispatcher, error) {
+	settings, err := config.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters, err := os.OpenFile(deadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+
+	return &Dispatcher{
+		settings:     settings,
+		httpClient:   &http.Client{Timeout: settings.timeout},
+		events:       events,
+		checkpointer: checkpointer,
+		deadLetters:  deadLetters,
+	}, nil
+}
+
+func (d *Dispatcher) Close() error {
+	return d.deadLetters.Close()
+}
+
+// Run delivers events until ctx is cancelled, returning nil, or the stream ends with
+// ErrEventStreamEnded. The checkpoint is shared by every hook: a transaction is checkpointed, in
+// ledger order, once each hook has delivered or dead-lettered its events, so an interrupted
+// transaction is delivered again on the next run, to the hooks that already had it too.
+func (d *Dispatcher) Run(ctx context.Context, startBlock uint64) error {
+	transactions, err := d.events.WatchFrom(ctx, d.checkpointer, startBlock)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var failOnce sync.Once
+	var failure error
+	fail := func(err error) {
+		failOnce.Do(func() {
+			failure = err
+			cancel()
+		})
+	}
+
+	var workers sync.WaitGroup
+	queues := make([]chan delivery, len(d.settings.hooks))
+	for i, hook := range d.settings.hooks {
+		queues[i] = make(chan delivery, hookQueueSize)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for delivery := range queues[i] {
+				if err := d.dispatch(runCtx, hook, delivery.payload, delivery.body); err != nil {
+					fail(err)
+				}
+				delivery.transaction.finish()
+			}
+		}()
+	}
+
+	// Transactions are checkpointed in the order they were read, whichever hook finishes last
+	pending := make(chan *pendingTransaction, hookQueueSize)
+	committed := make(chan struct{})
+	go func() {
+		defer close(committed)
+		for transaction := range pending {
+			select {
+			case <-transaction.done:
+			case <-runCtx.Done():
+				return
+			}
+			if runCtx.Err() != nil {
+				return
+			}
+			if err := d.checkpointer.CheckpointTransaction(transaction.blockNumber, transaction.transactionID); err != nil {
+				fail(fmt.Errorf("failed to checkpoint transaction %s: %w", transaction.transactionID, err))
+				return
+			}
+		}
+	}()
+
+	streamEnded := d.enqueue(runCtx, transactions, queues, pending, fail)
+
+	for _, queue := range queues {
+		close(queue)
+	}
+	workers.Wait()
+	close(pending)
+	<-committed
+
+	if failure != nil {
+		return failure
+	}
+	if ctx.Err() != nil || !streamEnded {
+		return nil
+	}
+	return personnelclient.ErrEventStreamEnded
+}
+
+// enqueue hands each event to the queues of the hooks subscribed to it, and each transaction to
+// pending to be checkpointed. It reports whether it stopped because the event stream ended.
+func (d *Dispatcher) enqueue(ctx context.Context, transactions <-chan []*personnelclient.Event, queues []chan delivery, pending chan<- *pendingTransaction, fail func(error)) bool {
+	for {
+		var events []*personnelclient.Event
+		select {
+		case received, ok := <-transactions:
+			if !ok {
+				return true
+			}
+			events = received
+		case <-ctx.Done():
+			return false
+		}
+
+		if len(events) == 0 {
+			continue
+		}
+
+		last := events[len(events)-1]
+		transaction := &pendingTransaction{
+			blockNumber:   last.BlockNumber,
+			transactionID: last.TransactionID,
+			done:          make(chan struct{}),
+		}
+
+		var deliveries []delivery
+		var targets []chan delivery
+		for i, event := range events {
+			payload := Payload{
+				DeliveryID:    fmt.Sprintf("%s-%d", event.TransactionID, i),
+				Event:         event.Name,
+				BlockNumber:   event.BlockNumber,
+				TransactionID: event.TransactionID,
+				Personnel:     event.Personnel,
+				Training:      event.Training,
+			}
+
+			body, err := json.Marshal(payload)
+			if err != nil {
+				fail(fmt.Errorf("failed to marshal payload: %w", err))
+				return false
+			}
+
+			for h, hook := range d.settings.hooks {
+				if slices.Contains(hook.events, payload.Event) {
+					deliveries = append(deliveries, delivery{payload: payload, body: body, transaction: transaction})
+					targets = append(targets, queues[h])
+				}
+			}
+		}
+
+		// Counted up front so an early finish cannot close done while deliveries are still queued
+		transaction.remaining.Store(int32(len(deliveries)) + 1)
+		for i, delivery := range deliveries {
+			select {
+			case targets[i] <- delivery:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		transaction.finish()
+
+		select {
+		case pending <- transaction:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// dispatch delivers payload to hook, dead-lettering it once delivery is given up on. Only a failure
+// to dead-letter is returned, as carrying on would lose the delivery.
+func (d *Dispatcher) dispatch(ctx context.Context, hook hook, payload Payload, body []byte) error {
+	attempts, err := d.deliver(ctx, hook, payload, body)
+	if err == nil || ctx.Err() != nil {
+		return nil
+	}
+
+	log.Printf("dead-lettering delivery %s to %s after %d attempt(s): %v", payload.DeliveryID, hook.name, attempts, err)
+
+	return d.deadLetter(hook, body, attempts, err)
+}
+
+// deliver POSTs body until it is accepted, the error is permanent, or the attempts run out
+func (d *Dispatcher) deliver(ctx context.Context, hook hook, payload Payload, body []byte) (int, error) {
+	backoff := d.settings.backoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		retry, err = d.post(ctx, hook, payload, body)
+		if err == nil {
+			return attempt, nil
+		}
+		if !retry || attempt == d.settings.attempts {
+			return attempt, err
+		}
+
+		log.Printf("delivery %s to %s failed, retrying in %s: %v", payload.DeliveryID, hook.name, backoff, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		}
+
+		backoff = min(backoff*2, d.settings.maxBackoff)
+	}
+}
+
+// post makes one attempt, reporting whether a failure is worth retrying
+func (d *Dispatcher) post(ctx context.Context, hook hook, payload Payload, body []byte) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Signed per attempt so the timestamp stays fresh on retries
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, payload.Event)
+	request.Header.Set(HeaderDelivery, payload.DeliveryID)
+	request.Header.Set(HeaderTimestamp, timestamp)
+	request.Header.Set(HeaderSignature, "sha256="+Sign(hook.secret, timestamp, body))
+
+	response, err := d.httpClient.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+
+	// Other client errors mean the request itself is wrong, sending it again will not help
+	retry := response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusRequestTimeout
+	return retry, fmt.Errorf("hook responded %s", response.Status)
+}
+
+func (d *Dispatcher) deadLetter(hook hook, body []byte, attempts int, deliveryErr error) error {
+	line, err := json.Marshal(DeadLetter{
+		Hook:     hook.name,
+		URL:      hook.url,
+		Payload:  body,
+		Attempts: attempts,
+		Error:    deliveryErr.Error(),
+		FailedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	d.deadLetterMu.Lock()
+	defer d.deadLetterMu.Unlock()
+
+	if _, err := d.deadLetters.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	if err := d.deadLetters.Sync(); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of timestamp and body as sent in the signature header
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -1,39 +1,138 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/fabricgateway"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/grpcapi"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/httpapi"
 	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/profile"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
+// serveShutdownTimeout bounds how long in-flight HTTP and gRPC requests are given to finish on shutdown
+const serveShutdownTimeout = 15 * time.Second
+
+// eventRetryDelay is the pause before listening again when the peer ends a chaincode event stream
+const eventRetryDelay = 5 * time.Second
+
+type globalOptions struct {
+	configPath string
+	profile    string
+	identity   string
+}
+
 func main() {
-	if len(os.Args) < 2 {
+	options, args := parseGlobalFlags(os.Args[1:])
+	if len(args) < 1 {
 		printUsage()
 		os.Exit(1)
 	}
 
-	gateway := fabricgateway.NewGateway()
-	defer gateway.Close()
+	// Cancelling the context on Ctrl-C aborts any in-flight gateway call
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	contract, err := gateway.GetContract()
+	config, err := loadGatewayConfig(options)
 	if err != nil {
-		log.Fatalf("failed to get contract: %v", err)
+		log.Fatalf("failed to load gateway config: %v", err)
 	}
 
-	client := personnelclient.NewPersonnelClient(contract)
+	// Wallet management works offline so is handled before connecting
+	if args[0] == "wallet" {
+		handleWallet(config.WalletPath, args[1:])
+		return
+	}
 
-	command := os.Args[1]
+	// Connects itself only when the metadata is not given as a file
+	if args[0] == "openapi" {
+		handleOpenAPI(ctx, config, args[1:])
+		return
+	}
+
+	// Credentials are verified without ledger access, by whoever they are presented to
+	if args[0] == "verify-credential" {
+		handleVerifyCredential(args[1:])
+		return
+	}
+
+	// The read model is local so querying it never needs the peer
+	if args[0] == "query" {
+		handleQuery(ctx, args[1:])
+		return
+	}
+
+	gateway, err := fabricgateway.NewGateway(config)
+	if err != nil {
+		log.Fatalf("failed to create gateway: %v", err)
+	}
+	defer gateway.Close()
+
+	if err := gateway.Connect(ctx); err != nil {
+		log.Fatalf("failed to connect gateway: %v", err)
+	}
+
+	// Calls reconnect when the peer becomes unavailable, which keeps the serve commands running
+	client := personnelclient.NewPersonnelClient(gateway.Contract())
+
+	command := args[0]
 
 	switch command {
+	case "serve":
+		handleServe(ctx, client, args[1:])
+	case "serve-grpc":
+		handleServeGRPC(ctx, gateway, config.ChaincodeName, client, args[1:])
+	case "project":
+		handleProject(ctx, gateway, config.ChaincodeName, args[1:])
+	case "replay":
+		handleReplay(ctx, gateway, config.ChaincodeName, args[1:])
+	case "webhooks":
+		handleWebhooks(ctx, gateway, config.ChaincodeName, args[1:])
 	case "get-personnel":
-		handleGetPersonnel(client, os.Args[2:])
+		handleGetPersonnel(ctx, client, args[1:])
 	case "enroll-cadet":
-		handleEnrollCadet(client, os.Args[2:])
+		handleEnrollCadet(ctx, client, args[1:])
 	case "complete-training":
-		handleCompleteTraining(client, os.Args[2:])
+		handleCompleteTraining(ctx, client, args[1:])
+	case "get-training":
+		handleGetTraining(ctx, client, args[1:])
+	case "transcript":
+		handleTranscript(ctx, client, args[1:])
+	case "report":
+		handleReport(ctx, client, args[1:])
+	case "export-credential":
+		handleExportCredential(ctx, gateway, config.ChaincodeName, client, args[1:])
+	case "add-course":
+		handleAddCourse(ctx, client, args[1:])
+	case "get-course":
+		handleGetCourse(ctx, client, args[1:])
+	case "list-courses":
+		handleListCourses(ctx, client)
+	case "import-cadets":
+		handleImportCadets(ctx, client, args[1:])
+	case "import-training":
+		handleImportTraining(ctx, client, args[1:])
+	case "migrate-state":
+		handleMigrateState(ctx, client, args[1:])
+	case "verify-indexes":
+		handleVerifyIndexes(ctx, client, args[1:])
+	case "repair-indexes":
+		handleRepairIndexes(ctx, client, args[1:])
 	default:
 		fmt.Printf("Unknown command: %s\n\n", command)
 		printUsage()
@@ -41,18 +140,267 @@ func main() {
 	}
 }
 
+// parseGlobalFlags reads the flags given before the command name
+func parseGlobalFlags(arguments []string) (globalOptions, []string) {
+	var options globalOptions
+
+	flags := flag.NewFlagSet("api", flag.ExitOnError)
+	flags.StringVar(&options.configPath, "config", os.Getenv("STARFLEET_CONFIG"), "path to a YAML or JSON profiles file")
+	flags.StringVar(&options.profile, "profile", os.Getenv("STARFLEET_PROFILE"), "name of the profile to use from the config file")
+	flags.StringVar(&options.identity, "identity", os.Getenv("IDENTITY"), "label of the wallet identity to act as")
+	flags.Usage = printUsage
+	flags.Parse(arguments)
+
+	return options, flags.Args()
+}
+
+// loadGatewayConfig layers the selected profile over the defaults, then environment variables over the profile
+func loadGatewayConfig(options globalOptions) (fabricgateway.Config, error) {
+	config := fabricgateway.DefaultConfig()
+
+	configPath := options.configPath
+	if configPath == "" {
+		if _, err := os.Stat(profile.DefaultPath); err == nil {
+			configPath = profile.DefaultPath
+		}
+	}
+
+	if configPath != "" {
+		file, err := profile.Load(configPath)
+		if err != nil {
+			return fabricgateway.Config{}, err
+		}
+		if err := file.Apply(options.profile, &config); err != nil {
+			return fabricgateway.Config{}, err
+		}
+	} else if options.profile != "" {
+		return fabricgateway.Config{}, fmt.Errorf("profile %s given but no config file found", options.profile)
+	}
+
+	if err := config.ApplyEnv(); err != nil {
+		return fabricgateway.Config{}, err
+	}
+
+	if options.identity != "" {
+		config.Identity = options.identity
+	}
+
+	return config, nil
+}
+
 func printUsage() {
 	fmt.Println("Usage:")
+	fmt.Println("  go run . [--config <file>] [--profile <name>] [--identity <label>] <command> [args]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  go run . serve [address]")
+	fmt.Println("  go run . serve-grpc [address]")
 	fmt.Println("  go run . get-personnel <personnel-id>")
 	fmt.Println("  go run . enroll-cadet <personnel-id> <name> <campus>")
 	fmt.Println("  go run . complete-training <record-id> <personnel-id> <campus> <training-code> <completed-at> <issued-by>")
+	fmt.Println("  go run . get-training <record-id>")
+	fmt.Println("  go run . transcript [--format text|json|html] [--out <file>] <personnel-id>")
+	fmt.Println("  go run . report roster --campus <campus> [--format table|csv|json] [--out <file>]")
+	fmt.Println("  go run . report completions [--code <training-code>] [--from <time>] [--to <time>] [--format table|csv|json] [--out <file>]")
+	fmt.Println("  go run . report ranks [--campus <campus>] [--format table|csv|json] [--out <file>]")
+	fmt.Println("  go run . export-credential [--chain <file.pem>] [--out <file>] <record-id>")
+	fmt.Println("  go run . verify-credential [--roots <file.pem>] [--endorser-roots <msp-id>=<file.pem>]... [--endorsers <msp-id,...>] [--block-hash <hex>] <credential.json>")
+	fmt.Println("  go run . add-course <training-code> <title> <campus> [description]")
+	fmt.Println("  go run . get-course <training-code>")
+	fmt.Println("  go run . list-courses")
+	fmt.Println("  go run . import-cadets <file.csv> [batch-size] [from-line]")
+	fmt.Println("  go run . import-training <file.csv|file.json|file.jsonl> [batch-size] [report-file]")
+	fmt.Println("  go run . migrate-state <from-version> [page-size]")
+	fmt.Println("  go run . verify-indexes [page-size]")
+	fmt.Println("  go run . repair-indexes [page-size]")
+	fmt.Println("  go run . openapi [--metadata <file>] [--format yaml|json] [--out <file>]")
+	fmt.Println("  go run . project [--db <file>] [--start-block <n>]")
+	fmt.Println("  go run . replay [--db <file>] [--start-block <n>] [--end-block <n>]")
+	fmt.Println("  go run . webhooks [--config <file>] [--checkpoint <file>] [--dead-letter <file>] [--start-block <n>]")
+	fmt.Println("  go run . query [--db <file>] [--format table|json] [--order <column>] [--desc] [--limit <n>] <personnel|training|history> [filter...]")
+	fmt.Println("  go run . wallet list")
+	fmt.Println("  go run . wallet import <label> <msp-id> <cert-path> <key-path>")
+	fmt.Println("  go run . wallet remove <label>")
 	fmt.Println("\nExamples:")
 	fmt.Println("  go run . get-personnel SF-001")
 	fmt.Println(`  go run . enroll-cadet SF-001 "Malcom Reynolds" Engineering`)
 	fmt.Println(`  go run . complete-training TR-001 SF-001 Engineering ENG-WARP-201 2024-06-01T12:00:00Z "Captain Janeway"`)
+	fmt.Println("  go run . transcript --format html --out ./SF-001.html SF-001")
+	fmt.Println("  go run . report roster --campus Engineering --format csv --out ./engineering.csv")
+	fmt.Println("  go run . report completions --code ENG-WARP-201 --from 2024-01-01T00:00:00Z --to 2024-12-31T23:59:59Z")
+	fmt.Println("  go run . report ranks --format json")
+	fmt.Println("  go run . --identity registrar export-credential --chain ./crypto-config/ca/ca.pem TR-001")
+	fmt.Println("  go run . verify-credential --roots ./academy-root-ca.pem --endorser-roots orgMSP=./academy-root-ca.pem --endorsers orgMSP ./TR-001.credential.json")
+	fmt.Println("  go run . migrate-state 1")
+	fmt.Println("  go run . import-cadets ./intake-2024.csv 200")
+	fmt.Println("  go run . import-training ./results-term1.jsonl 50 ./term1-report.csv")
+	fmt.Println(`  go run . add-course ENG-WARP-201 "Advanced Warp Theory" Engineering`)
+	fmt.Println("  go run . --profile engineering-dev get-personnel SF-001")
+	fmt.Println("  go run . --identity registrar serve 127.0.0.1:8080")
+	fmt.Println("  go run . --identity registrar serve-grpc 127.0.0.1:9090")
+	fmt.Println("  go run . openapi --format json --out ./openapi.json")
+	fmt.Println("  go run . project --db ./academy.db")
+	fmt.Println("  go run . replay --db ./academy-rebuilt.db")
+	fmt.Println("  go run . webhooks --config ./webhooks.yaml")
+	fmt.Println(`  go run . query --order completed_at --desc training training_code=ENG-WARP-201 "completed_at>=2024-01-01"`)
+	fmt.Println("  go run . query --format json personnel campus=Engineering status=Active")
+	fmt.Println("  go run . wallet import registrar orgMSP ./registrar/cert.pem ./registrar/key_sk")
+	fmt.Println(`  go run . --identity registrar enroll-cadet SF-002 "Kaylee Frye" Engineering`)
+	fmt.Println("  go run . --identity admin repair-indexes 200")
+}
+
+// handleServe runs the HTTP API until interrupted, then lets in-flight requests finish
+func handleServe(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
+	address := os.Getenv("HTTP_ADDRESS")
+	if len(args) > 0 {
+		address = args[0]
+	}
+	if address == "" {
+		address = "127.0.0.1:8080"
+	}
+
+	tlsConfig, err := loadServeTLS(address)
+	if err != nil {
+		log.Fatalf("failed to load HTTP API TLS configuration: %v", err)
+	}
+
+	server := httpapi.NewServer(address, client, tlsConfig)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		log.Fatalf("failed to serve HTTP API: %v", err)
+	case <-ctx.Done():
+	}
+
+	log.Printf("shutting down HTTP API")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP API did not shut down cleanly: %v", err)
+	}
+}
+
+// loadServeTLS reads the TLS settings shared by serve and serve-grpc. SERVE_TLS_CERT and
+// SERVE_TLS_KEY enable TLS, SERVE_TLS_CLIENT_CA additionally requires clients to present a
+// certificate it issued. Every caller acts with the API's Fabric identity, so serving without TLS
+// is only intended for loopback addresses and is warned about otherwise.
+func loadServeTLS(address string) (*tls.Config, error) {
+	certPath := os.Getenv("SERVE_TLS_CERT")
+	keyPath := os.Getenv("SERVE_TLS_KEY")
+	clientCAPath := os.Getenv("SERVE_TLS_CLIENT_CA")
+
+	if certPath == "" && keyPath == "" {
+		if clientCAPath != "" {
+			return nil, fmt.Errorf("SERVE_TLS_CLIENT_CA requires SERVE_TLS_CERT and SERVE_TLS_KEY")
+		}
+		if !isLoopback(address) {
+			log.Printf("WARNING: serving on %s without TLS or client authentication, anyone who can reach it acts with this identity", address)
+		}
+		return nil, nil
+	}
+	if certPath == "" || keyPath == "" {
+		return nil, fmt.Errorf("SERVE_TLS_CERT and SERVE_TLS_KEY must be set together")
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load key pair: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+	}
+
+	if clientCAPath != "" {
+		pem, err := os.ReadFile(clientCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", clientCAPath)
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	} else if !isLoopback(address) {
+		log.Printf("WARNING: serving on %s without client authentication, set SERVE_TLS_CLIENT_CA to require client certificates", address)
+	}
+
+	return tlsConfig, nil
 }
 
-func handleGetPersonnel(client *personnelclient.PersonnelClient, args []string) {
+// isLoopback reports whether a listen address only accepts local connections
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// handleServeGRPC runs the gRPC API until interrupted. Event streams never finish on their own,
+// so a graceful stop that overruns the timeout is forced
+func handleServeGRPC(ctx context.Context, gateway *fabricgateway.Gateway, chaincodeName string, client *personnelclient.PersonnelClient, args []string) {
+	address := os.Getenv("GRPC_ADDRESS")
+	if len(args) > 0 {
+		address = args[0]
+	}
+	if address == "" {
+		address = "127.0.0.1:9090"
+	}
+
+	tlsConfig, err := loadServeTLS(address)
+	if err != nil {
+		log.Fatalf("failed to load gRPC API TLS configuration: %v", err)
+	}
+
+	var options []grpc.ServerOption
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	service := grpcapi.NewService(client, personnelclient.NewEventWatcher(gateway, chaincodeName))
+	server := grpcapi.NewServer(service, options...)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- grpcapi.Serve(server, address)
+	}()
+
+	select {
+	case err := <-serverErr:
+		log.Fatalf("failed to serve gRPC API: %v", err)
+	case <-ctx.Done():
+	}
+
+	log.Printf("shutting down gRPC API")
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(serveShutdownTimeout):
+		log.Printf("gRPC API did not shut down cleanly, closing open streams")
+		server.Stop()
+	}
+}
+
+func handleGetPersonnel(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
 	if len(args) < 1 {
 		fmt.Println("Error: personnel-id is required")
 		fmt.Println("Usage: go run . get-personnel <personnel-id>")
@@ -61,7 +409,7 @@ func handleGetPersonnel(client *personnelclient.PersonnelClient, args []string)
 
 	personnelID := args[0]
 
-	personnel, err := client.GetPersonnel(personnelID)
+	personnel, err := client.GetPersonnel(ctx, personnelID)
 	if err != nil {
 		log.Fatalf("failed to get personnel: %v", err)
 	}
@@ -74,7 +422,7 @@ func handleGetPersonnel(client *personnelclient.PersonnelClient, args []string)
 	fmt.Printf("  Status: %s\n", personnel.Status)
 }
 
-func handleEnrollCadet(client *personnelclient.PersonnelClient, args []string) {
+func handleEnrollCadet(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
 	if len(args) < 3 {
 		fmt.Println("Error: personnel-id, name, and campus are required")
 		fmt.Println(`Usage: go run . enroll-cadet <personnel-id> <name> <campus>`)
@@ -85,7 +433,7 @@ func handleEnrollCadet(client *personnelclient.PersonnelClient, args []string) {
 	name := args[1]
 	campus := args[2]
 
-	personnel, err := client.EnrollCadet(personnelID, name, campus)
+	personnel, err := client.EnrollCadet(ctx, personnelID, name, campus)
 	if err != nil {
 		log.Fatalf("failed to enroll cadet: %v", err)
 	}
@@ -98,7 +446,7 @@ func handleEnrollCadet(client *personnelclient.PersonnelClient, args []string) {
 	fmt.Printf("  Status: %s\n", personnel.Status)
 }
 
-func handleCompleteTraining(client *personnelclient.PersonnelClient, args []string) {
+func handleCompleteTraining(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
 	if len(args) < 6 {
 		fmt.Println("Error: record-id, personnel-id, campus, training-code, completed-at, and issued-by are required")
 		fmt.Println(`Usage: go run . complete-training <record-id> <personnel-id> <campus> <training-code> <completed-at> <issued-by>`)
@@ -112,7 +460,7 @@ func handleCompleteTraining(client *personnelclient.PersonnelClient, args []stri
 	completedAt := args[4]
 	issuedBy := args[5]
 
-	training, err := client.CompleteTraining(recordID, personnelID, campus, trainingCode, completedAt, issuedBy)
+	training, err := client.CompleteTraining(ctx, recordID, personnelID, campus, trainingCode, completedAt, issuedBy)
 	if err != nil {
 		log.Fatalf("failed to complete training: %v", err)
 	}
@@ -126,3 +474,223 @@ func handleCompleteTraining(client *personnelclient.PersonnelClient, args []stri
 	fmt.Printf("  Issued By:     %s\n", training.IssuedBy)
 	fmt.Printf("  Status:        %s\n", training.Status)
 }
+
+func handleGetTraining(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: record-id is required")
+		fmt.Println("Usage: go run . get-training <record-id>")
+		os.Exit(1)
+	}
+
+	training, err := client.GetTraining(ctx, args[0])
+	if err != nil {
+		log.Fatalf("failed to get training: %v", err)
+	}
+
+	fmt.Printf("Training Info:\n")
+	fmt.Printf("  Record ID:     %s\n", training.RecordID)
+	fmt.Printf("  Personnel ID:  %s\n", training.PersonnelID)
+	fmt.Printf("  Campus:        %s\n", training.Campus)
+	fmt.Printf("  Training Code: %s\n", training.TrainingCode)
+	fmt.Printf("  Completed At:  %s\n", training.CompletedAt)
+	fmt.Printf("  Issued By:     %s\n", training.IssuedBy)
+	fmt.Printf("  Status:        %s\n", training.Status)
+}
+
+func handleAddCourse(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
+	if len(args) < 3 {
+		fmt.Println("Error: training-code, title, and campus are required")
+		fmt.Println(`Usage: go run . add-course <training-code> <title> <campus> [description]`)
+		os.Exit(1)
+	}
+
+	description := ""
+	if len(args) > 3 {
+		description = args[3]
+	}
+
+	course, err := client.AddCourse(ctx, args[0], args[1], args[2], description)
+	if err != nil {
+		log.Fatalf("failed to add course: %v", err)
+	}
+
+	fmt.Printf("Course added successfully:\n")
+	printCourse(course)
+}
+
+func handleGetCourse(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: training-code is required")
+		fmt.Println("Usage: go run . get-course <training-code>")
+		os.Exit(1)
+	}
+
+	course, err := client.GetCourse(ctx, args[0])
+	if err != nil {
+		log.Fatalf("failed to get course: %v", err)
+	}
+
+	fmt.Printf("Course Info:\n")
+	printCourse(course)
+}
+
+func handleListCourses(ctx context.Context, client *personnelclient.PersonnelClient) {
+	courses, err := client.ListCourses(ctx)
+	if err != nil {
+		log.Fatalf("failed to list courses: %v", err)
+	}
+
+	if len(courses) == 0 {
+		fmt.Println("No courses in the catalogue")
+		return
+	}
+
+	for _, course := range courses {
+		fmt.Printf("  %-16s %-12s %s\n", course.TrainingCode, course.Campus, course.Title)
+	}
+}
+
+func handleMigrateState(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: from-version is required")
+		fmt.Println("Usage: go run . migrate-state <from-version> [page-size]")
+		os.Exit(1)
+	}
+
+	fromVersion, err := strconv.Atoi(args[0])
+	if err != nil || fromVersion < 0 {
+		fmt.Println("Error: from-version must be a number, 0 for documents written before versioning")
+		fmt.Println("Usage: go run . migrate-state <from-version> [page-size]")
+		os.Exit(1)
+	}
+
+	pageSize := 100
+	if len(args) > 1 {
+		size, err := strconv.Atoi(args[1])
+		if err != nil || size < 1 {
+			fmt.Println("Error: page-size must be a positive number")
+			fmt.Println("Usage: go run . migrate-state <from-version> [page-size]")
+			os.Exit(1)
+		}
+		pageSize = size
+	}
+
+	// Each page is its own transaction, so an interrupted migration can simply be run again
+	var scanned, migrated int
+	bookmark := ""
+	for page := 1; ; page++ {
+		result, err := client.MigrateState(ctx, fromVersion, pageSize, bookmark)
+		if err != nil {
+			log.Fatalf("failed to migrate state (page %d): %v", page, err)
+		}
+
+		scanned += result.Scanned
+		migrated += result.Migrated
+		fmt.Printf("  page %d: scanned %d, migrated %d\n", page, result.Scanned, result.Migrated)
+
+		if result.Done {
+			break
+		}
+		bookmark = result.Bookmark
+	}
+
+	fmt.Printf("Migration from schema version %d complete:\n", fromVersion)
+	fmt.Printf("  Scanned:  %d\n", scanned)
+	fmt.Printf("  Migrated: %d\n", migrated)
+}
+
+func handleVerifyIndexes(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
+	pageSize := 100
+	if len(args) > 0 {
+		size, err := strconv.Atoi(args[0])
+		if err != nil || size < 1 {
+			fmt.Println("Error: page-size must be a positive number")
+			fmt.Println("Usage: go run . verify-indexes [page-size]")
+			os.Exit(1)
+		}
+		pageSize = size
+	}
+
+	report := &domain.IndexReport{}
+	bookmark := ""
+	for page := 1; ; page++ {
+		result, err := client.VerifyIndexes(ctx, pageSize, bookmark)
+		if err != nil {
+			log.Fatalf("failed to verify indexes (page %d): %v", page, err)
+		}
+
+		report.Documents += result.Documents
+		report.IndexEntries += result.IndexEntries
+		report.Orphaned = append(report.Orphaned, result.Orphaned...)
+		report.Missing = append(report.Missing, result.Missing...)
+
+		if result.Done {
+			break
+		}
+		bookmark = result.Bookmark
+	}
+
+	fmt.Printf("Index Verification:\n")
+	fmt.Printf("  Documents:     %d\n", report.Documents)
+	fmt.Printf("  Index Entries: %d\n", report.IndexEntries)
+	fmt.Printf("  Orphaned:      %d\n", len(report.Orphaned))
+	fmt.Printf("  Missing:       %d\n", len(report.Missing))
+
+	for _, problem := range report.Orphaned {
+		fmt.Printf("  orphaned %-22s %-40s %s\n", problem.Index, strings.Join(problem.Attributes, "~"), problem.Reason)
+	}
+	for _, problem := range report.Missing {
+		fmt.Printf("  missing  %-22s %-40s %s\n", problem.Index, strings.Join(problem.Attributes, "~"), problem.Reason)
+	}
+
+	if len(report.Orphaned) > 0 || len(report.Missing) > 0 {
+		fmt.Println("Indexes are inconsistent, run repair-indexes to fix them")
+		os.Exit(2)
+	}
+	fmt.Println("Indexes are consistent")
+}
+
+func handleRepairIndexes(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
+	pageSize := 100
+	if len(args) > 0 {
+		size, err := strconv.Atoi(args[0])
+		if err != nil || size < 1 {
+			fmt.Println("Error: page-size must be a positive number")
+			fmt.Println("Usage: go run . repair-indexes [page-size]")
+			os.Exit(1)
+		}
+		pageSize = size
+	}
+
+	// Each page is its own transaction, so an interrupted repair can simply be run again
+	var scanned, added, removed int
+	bookmark := ""
+	for page := 1; ; page++ {
+		result, err := client.RepairIndexes(ctx, pageSize, bookmark)
+		if err != nil {
+			log.Fatalf("failed to repair indexes (page %d): %v", page, err)
+		}
+
+		scanned += result.Scanned
+		added += result.Added
+		removed += result.Removed
+		fmt.Printf("  page %d: scanned %d, added %d, removed %d\n", page, result.Scanned, result.Added, result.Removed)
+
+		if result.Done {
+			break
+		}
+		bookmark = result.Bookmark
+	}
+
+	fmt.Printf("Index repair complete:\n")
+	fmt.Printf("  Scanned: %d\n", scanned)
+	fmt.Printf("  Added:   %d\n", added)
+	fmt.Printf("  Removed: %d\n", removed)
+}
+
+func printCourse(course *domain.Course) {
+	fmt.Printf("  Training Code: %s\n", course.TrainingCode)
+	fmt.Printf("  Title:         %s\n", course.Title)
+	fmt.Printf("  Campus:        %s\n", course.Campus)
+	fmt.Printf("  Description:   %s\n", course.Description)
+}
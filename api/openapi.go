@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/fabricgateway"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/httpapi"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/openapi"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+	"gopkg.in/yaml.v3"
+)
+
+// handleOpenAPI writes the OpenAPI document for the serve command's HTTP API. The metadata is read
+// from the deployed chaincode unless a saved copy is given, which needs no network.
+func handleOpenAPI(ctx context.Context, config fabricgateway.Config, args []string) {
+	flags := flag.NewFlagSet("openapi", flag.ExitOnError)
+	metadataPath := flags.String("metadata", "", "contract metadata JSON file, fetched from the chaincode when not set")
+	format := flags.String("format", "yaml", "output format, yaml or json")
+	outPath := flags.String("out", "", "file to write, standard output when not set")
+	flags.Parse(args)
+
+	if *format != "yaml" && *format != "json" {
+		fmt.Println("Error: format must be yaml or json")
+		fmt.Println("Usage: go run . openapi [--metadata <file>] [--format yaml|json] [--out <file>]")
+		os.Exit(1)
+	}
+
+	var metadata []byte
+	if *metadataPath != "" {
+		var err error
+		metadata, err = os.ReadFile(*metadataPath)
+		if err != nil {
+			log.Fatalf("failed to read metadata: %v", err)
+		}
+	} else {
+		metadata = fetchMetadata(ctx, config)
+	}
+
+	document, err := openapi.Generate(metadata, httpapi.Routes(), openapi.Info{
+		Title:       "Starfleet Personnel API",
+		Description: "HTTP API served by `api serve`, backed by the starfleet personnel chaincode",
+	})
+	if err != nil {
+		log.Fatalf("failed to generate OpenAPI document: %v", err)
+	}
+
+	var output []byte
+	if *format == "json" {
+		output, err = json.MarshalIndent(document, "", "  ")
+		output = append(output, '\n')
+	} else {
+		var buffer bytes.Buffer
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		err = encoder.Encode(document)
+		output = buffer.Bytes()
+	}
+	if err != nil {
+		log.Fatalf("failed to marshal OpenAPI document: %v", err)
+	}
+
+	if *outPath == "" {
+		os.Stdout.Write(output)
+		return
+	}
+	if err := os.WriteFile(*outPath, output, 0o644); err != nil {
+		log.Fatalf("failed to write OpenAPI document: %v", err)
+	}
+	fmt.Printf("OpenAPI document written to %s\n", *outPath)
+}
+
+func fetchMetadata(ctx context.Context, config fabricgateway.Config) []byte {
+	gateway, err := fabricgateway.NewGateway(config)
+	if err != nil {
+		log.Fatalf("failed to create gateway: %v", err)
+	}
+	defer gateway.Close()
+
+	contract, err := gateway.GetContract(ctx)
+	if err != nil {
+		log.Fatalf("failed to get contract: %v", err)
+	}
+
+	metadata, err := personnelclient.NewPersonnelClient(contract).GetMetadata(ctx)
+	if err != nil {
+		log.Fatalf("failed to get contract metadata: %v", err)
+	}
+
+	return metadata
+}
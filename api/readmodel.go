@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/blockreplay"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/fabricgateway"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/readmodel"
+)
+
+func readModelPath() string {
+	if path := os.Getenv("READ_MODEL_PATH"); path != "" {
+		return path
+	}
+	return readmodel.DefaultPath
+}
+
+// handleProject keeps the read model up to date with the chaincode's events until interrupted
+func handleProject(ctx context.Context, gateway *fabricgateway.Gateway, chaincodeName string, args []string) {
+	flags := flag.NewFlagSet("project", flag.ExitOnError)
+	dbPath := flags.String("db", readModelPath(), "read model database file")
+	startBlock := flags.Uint64("start-block", 0, "block to start from when the read model is empty")
+	flags.Parse(args)
+
+	store, err := readmodel.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("failed to open read model: %v", err)
+	}
+	defer store.Close()
+
+	projector := readmodel.NewProjector(store, personnelclient.NewEventWatcher(gateway, chaincodeName))
+
+	for {
+		err := projector.Run(ctx, *startBlock)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, personnelclient.ErrEventStreamEnded) && !errors.Is(err, fabricgateway.ErrPeerUnreachable) {
+			log.Fatalf("failed to project events: %v", err)
+		}
+
+		log.Printf("%v, listening again in %s", err, eventRetryDelay)
+		select {
+		case <-time.After(eventRetryDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleReplay rebuilds a read model from the ledger's blocks. It only writes to a new, empty
+// database, which the project command can then keep up to date.
+func handleReplay(ctx context.Context, gateway *fabricgateway.Gateway, chaincodeName string, args []string) {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	dbPath := flags.String("db", readModelPath(), "read model database file to create")
+	startBlock := flags.Uint64("start-block", 0, "first block to replay")
+	endBlock := flags.Uint64("end-block", 0, "last block to replay, the current last block when 0")
+	flags.Parse(args)
+
+	store, err := readmodel.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("failed to open read model: %v", err)
+	}
+	defer store.Close()
+
+	checkpoint, err := store.Checkpoint(ctx)
+	if err != nil {
+		log.Fatalf("failed to read checkpoint: %v", err)
+	}
+	if checkpoint.BlockNumber() != 0 || checkpoint.TransactionID() != "" {
+		log.Fatalf("read model %s already holds data up to block %d, replay into a new file", *dbPath, checkpoint.BlockNumber())
+	}
+
+	network, err := gateway.GetNetwork(ctx)
+	if err != nil {
+		log.Fatalf("failed to get network: %v", err)
+	}
+
+	replayer := blockreplay.NewReplayer(network, chaincodeName)
+
+	last := *endBlock
+	if last == 0 {
+		height, err := personnelclient.NewLedger(network).Height(ctx)
+		if err != nil {
+			log.Fatalf("failed to get chain height: %v", err)
+		}
+		last = height - 1
+	}
+
+	fmt.Printf("Replaying blocks %d to %d into %s\n", *startBlock, last, *dbPath)
+
+	var transactions, writes int
+	err = replayer.Replay(ctx, *startBlock, last, func(ctx context.Context, blockNumber uint64, decoded []*blockreplay.Transaction) error {
+		if err := store.ApplyBlock(ctx, blockNumber, decoded); err != nil {
+			return err
+		}
+
+		transactions += len(decoded)
+		for _, transaction := range decoded {
+			writes += len(transaction.Writes)
+		}
+		if blockNumber%100 == 0 {
+			log.Printf("replayed block %d of %d", blockNumber, last)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("failed to replay blocks: %v", err)
+	}
+
+	fmt.Printf("Replay Complete:\n")
+	fmt.Printf("  Blocks:       %d\n", last-*startBlock+1)
+	fmt.Printf("  Transactions: %d\n", transactions)
+	fmt.Printf("  Writes:       %d\n", writes)
+}
+
+// handleQuery reads the local read model only, it never connects to the peer
+func handleQuery(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	dbPath := flags.String("db", readModelPath(), "read model database file")
+	format := flags.String("format", "table", "output format, table or json")
+	orderBy := flags.String("order", "", "column to order by, the table's first column when not set")
+	descending := flags.Bool("desc", false, "order descending")
+	limit := flags.Int("limit", 0, "maximum rows to return, all when 0")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 || (*format != "table" && *format != "json") {
+		fmt.Println("Error: a table of personnel, training or history is required, format must be table or json")
+		fmt.Println("Usage: go run . query [--db <file>] [--format table|json] [--order <column>] [--desc] [--limit <n>] <table> [filter...]")
+		os.Exit(1)
+	}
+
+	query := readmodel.Query{
+		Table:      flags.Arg(0),
+		OrderBy:    *orderBy,
+		Descending: *descending,
+		Limit:      *limit,
+	}
+	for _, arg := range flags.Args()[1:] {
+		filter, err := readmodel.ParseFilter(arg)
+		if err != nil {
+			log.Fatalf("invalid filter: %v", err)
+		}
+		query.Filters = append(query.Filters, filter)
+	}
+
+	store, err := readmodel.OpenReadOnly(*dbPath)
+	if err != nil {
+		log.Fatalf("failed to open read model: %v", err)
+	}
+	defer store.Close()
+
+	result, err := store.Query(ctx, query)
+	if err != nil {
+		log.Fatalf("failed to query read model: %v", err)
+	}
+
+	if *format == "json" {
+		rows := make([]map[string]any, len(result.Rows))
+		for i, row := range result.Rows {
+			rows[i] = make(map[string]any, len(row))
+			for j, column := range result.Columns {
+				rows[i][column] = row[j]
+			}
+		}
+
+		output, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to marshal rows: %v", err)
+		}
+		fmt.Println(string(output))
+		return
+	}
+
+	if len(result.Rows) == 0 {
+		fmt.Println("No matching rows")
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.ToUpper(strings.Join(result.Columns, "\t")))
+	for _, row := range result.Rows {
+		values := make([]string, len(row))
+		for i, value := range row {
+			values[i] = fmt.Sprint(value)
+		}
+		fmt.Fprintln(writer, strings.Join(values, "\t"))
+	}
+	writer.Flush()
+
+	fmt.Printf("\n%d row(s)\n", len(result.Rows))
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+)
+
+const reportUsage = "Usage: go run . report <roster|completions|ranks> [--format table|csv|json] [--out <file>] [args]"
+
+// report is one report's output, rows are written as a table or CSV and value as JSON
+type report struct {
+	columns []string
+	rows    [][]string
+	value   any
+	// summary follows a table, CSV and JSON stay machine readable
+	summary string
+}
+
+func handleReport(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: report name is required")
+		fmt.Println(reportUsage)
+		os.Exit(1)
+	}
+
+	flags := flag.NewFlagSet("report "+args[0], flag.ExitOnError)
+	format := flags.String("format", "table", "output format, table, csv or json")
+	outPath := flags.String("out", "", "file to write, standard output when not set")
+
+	var build func() (*report, error)
+	switch args[0] {
+	case "roster":
+		campus := flags.String("campus", "", "campus to list, required")
+		build = func() (*report, error) { return rosterReport(ctx, client, *campus) }
+	case "completions":
+		code := flags.String("code", "", "training code, every code when not set")
+		from := flags.String("from", "", "earliest completion to count, RFC3339")
+		to := flags.String("to", "", "latest completion to count, RFC3339")
+		build = func() (*report, error) { return completionsReport(ctx, client, *code, *from, *to) }
+	case "ranks":
+		campus := flags.String("campus", "", "campus to count, the whole academy when not set")
+		build = func() (*report, error) { return ranksReport(ctx, client, *campus) }
+	default:
+		fmt.Printf("Unknown report: %s\n", args[0])
+		fmt.Println(reportUsage)
+		os.Exit(1)
+	}
+	flags.Parse(args[1:])
+
+	if *format != "table" && *format != "csv" && *format != "json" {
+		fmt.Println("Error: format must be table, csv or json")
+		fmt.Println(reportUsage)
+		os.Exit(1)
+	}
+
+	result, err := build()
+	if err != nil {
+		log.Fatalf("failed to build %s report: %v", args[0], err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatalf("failed to create %s: %v", *outPath, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := writeReport(out, *format, result); err != nil {
+		log.Fatalf("failed to write %s report: %v", args[0], err)
+	}
+
+	if *outPath != "" {
+		fmt.Printf("Report %s written to %s\n", args[0], *outPath)
+	}
+}
+
+func rosterReport(ctx context.Context, client *personnelclient.PersonnelClient, campus string) (*report, error) {
+	if campus == "" {
+		return nil, fmt.Errorf("--campus is required")
+	}
+
+	personnel, err := client.GetRoster(ctx, campus)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &report{
+		columns: []string{"personnelID", "name", "rank", "status"},
+		value:   personnel,
+		summary: fmt.Sprintf("%d personnel at %s", len(personnel), campus),
+	}
+	for _, member := range personnel {
+		result.rows = append(result.rows, []string{member.PersonnelID, member.Name, member.Rank, member.Status})
+	}
+
+	return result, nil
+}
+
+func completionsReport(ctx context.Context, client *personnelclient.PersonnelClient, code, from, to string) (*report, error) {
+	stats, err := client.GetCompletionStats(ctx, code, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &report{
+		columns: []string{"trainingCode", "campus", "completions", "personnel"},
+		value:   stats,
+		summary: fmt.Sprintf("%d completion(s) by %d personnel", stats.Completions, stats.Personnel),
+	}
+	for _, count := range stats.Counts {
+		result.rows = append(result.rows, []string{count.TrainingCode, count.Campus, strconv.Itoa(count.Completions), strconv.Itoa(count.Personnel)})
+	}
+
+	return result, nil
+}
+
+func ranksReport(ctx context.Context, client *personnelclient.PersonnelClient, campus string) (*report, error) {
+	distribution, err := client.GetRankDistribution(ctx, campus)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := "the academy"
+	if campus != "" {
+		scope = campus
+	}
+
+	result := &report{
+		columns: []string{"rank", "total", "active", "share"},
+		value:   distribution,
+		summary: fmt.Sprintf("%d personnel at %s", distribution.Total, scope),
+	}
+	for _, count := range distribution.Ranks {
+		share := float64(count.Total) / float64(distribution.Total) * 100
+		result.rows = append(result.rows, []string{count.Rank, strconv.Itoa(count.Total), strconv.Itoa(count.Active), fmt.Sprintf("%.1f%%", share)})
+	}
+
+	return result, nil
+}
+
+func writeReport(out io.Writer, format string, result *report) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result.value)
+	case "csv":
+		writer := csv.NewWriter(out)
+		writer.Write(result.columns)
+		writer.WriteAll(result.rows)
+		return writer.Error()
+	}
+
+	if len(result.rows) == 0 {
+		_, err := fmt.Fprintln(out, "No matching rows")
+		return err
+	}
+
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.ToUpper(strings.Join(result.columns, "\t")))
+	for _, row := range result.rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(out, "\n%s\n", result.summary)
+	return err
+}
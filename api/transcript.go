@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/transcript"
+)
+
+func handleTranscript(ctx context.Context, client *personnelclient.PersonnelClient, args []string) {
+	flags := flag.NewFlagSet("transcript", flag.ExitOnError)
+	format := flags.String("format", "text", "output format, text, json or html")
+	outPath := flags.String("out", "", "file to write, standard output when not set")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 || (*format != "text" && *format != "json" && *format != "html") {
+		fmt.Println("Error: personnel-id is required, format must be text, json or html")
+		fmt.Println("Usage: go run . transcript [--format text|json|html] [--out <file>] <personnel-id>")
+		os.Exit(1)
+	}
+
+	record, err := client.GetTranscript(ctx, flags.Arg(0))
+	if err != nil {
+		log.Fatalf("failed to get transcript: %v", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatalf("failed to create %s: %v", *outPath, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(record)
+	case "html":
+		err = transcript.WriteHTML(out, record, time.Now())
+	default:
+		err = transcript.WriteText(out, record, time.Now())
+	}
+	if err != nil {
+		log.Fatalf("failed to write transcript: %v", err)
+	}
+
+	if *outPath != "" {
+		fmt.Printf("Transcript for %s written to %s\n", record.Personnel.PersonnelID, *outPath)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/wallet"
+)
+
+func handleWallet(walletPath string, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: wallet subcommand is required")
+		fmt.Println("Usage: go run . wallet <list|import|remove> [args]")
+		os.Exit(1)
+	}
+
+	w, err := wallet.Open(walletPath)
+	if err != nil {
+		log.Fatalf("failed to open wallet: %v", err)
+	}
+
+	switch args[0] {
+	case "list":
+		handleWalletList(w)
+	case "import":
+		handleWalletImport(w, args[1:])
+	case "remove":
+		handleWalletRemove(w, args[1:])
+	default:
+		fmt.Printf("Unknown wallet subcommand: %s\n", args[0])
+		fmt.Println("Usage: go run . wallet <list|import|remove> [args]")
+		os.Exit(1)
+	}
+}
+
+func handleWalletList(w *wallet.Wallet) {
+	labels, err := w.List()
+	if err != nil {
+		log.Fatalf("failed to list wallet: %v", err)
+	}
+
+	if len(labels) == 0 {
+		fmt.Println("Wallet is empty")
+		return
+	}
+
+	fmt.Printf("Wallet identities:\n")
+	for _, label := range labels {
+		id, err := w.Get(label)
+		if err != nil {
+			fmt.Printf("  %-20s (unreadable: %v)\n", label, err)
+			continue
+		}
+		fmt.Printf("  %-20s %s\n", label, id.MSPID)
+	}
+}
+
+func handleWalletImport(w *wallet.Wallet, args []string) {
+	if len(args) < 4 {
+		fmt.Println("Error: label, msp-id, cert-path and key-path are required")
+		fmt.Println("Usage: go run . wallet import <label> <msp-id> <cert-path> <key-path>")
+		os.Exit(1)
+	}
+
+	label := args[0]
+	mspID := args[1]
+	certPath := args[2]
+	keyPath := args[3]
+
+	certificatePEM, err := os.ReadFile(certPath)
+	if err != nil {
+		log.Fatalf("failed to read certificate: %v", err)
+	}
+
+	privateKeyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		log.Fatalf("failed to read private key: %v", err)
+	}
+
+	id, err := wallet.NewX509Identity(mspID, certificatePEM, privateKeyPEM)
+	if err != nil {
+		log.Fatalf("failed to create identity: %v", err)
+	}
+
+	if err := w.Put(label, id); err != nil {
+		log.Fatalf("failed to import identity: %v", err)
+	}
+
+	fmt.Printf("Identity %s imported (%s)\n", label, mspID)
+}
+
+func handleWalletRemove(w *wallet.Wallet, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: label is required")
+		fmt.Println("Usage: go run . wallet remove <label>")
+		os.Exit(1)
+	}
+
+	if err := w.Remove(args[0]); err != nil {
+		log.Fatalf("failed to remove identity: %v", err)
+	}
+
+	fmt.Printf("Identity %s removed\n", args[0])
+}
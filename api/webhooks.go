@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/fabricgateway"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/personnelclient"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/api/internal/webhook"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// handleWebhooks delivers chaincode events to the configured hooks until interrupted
+func handleWebhooks(ctx context.Context, gateway *fabricgateway.Gateway, chaincodeName string, args []string) {
+	configPath := os.Getenv("WEBHOOK_CONFIG")
+	if configPath == "" {
+		configPath = webhook.DefaultPath
+	}
+
+	flags := flag.NewFlagSet("webhooks", flag.ExitOnError)
+	flags.StringVar(&configPath, "config", configPath, "webhooks file")
+	checkpointPath := flags.String("checkpoint", "./webhooks.checkpoint", "file recording the last delivered transaction")
+	deadLetterPath := flags.String("dead-letter", "./webhooks.deadletter.jsonl", "file the failed deliveries are appended to")
+	startBlock := flags.Uint64("start-block", 0, "block to start from when there is no checkpoint")
+	flags.Parse(args)
+
+	config, err := webhook.LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("failed to load webhooks: %v", err)
+	}
+
+	checkpointer, err := client.NewFileCheckpointer(*checkpointPath)
+	if err != nil {
+		log.Fatalf("failed to open checkpoint: %v", err)
+	}
+	defer checkpointer.Close()
+
+	dispatcher, err := webhook.NewDispatcher(config, personnelclient.NewEventWatcher(gateway, chaincodeName), checkpointer, *deadLetterPath)
+	if err != nil {
+		log.Fatalf("failed to create webhook dispatcher: %v", err)
+	}
+	defer dispatcher.Close()
+
+	for {
+		if checkpointer.TransactionID() != "" {
+			log.Printf("delivering events after transaction %s in block %d", checkpointer.TransactionID(), checkpointer.BlockNumber())
+		}
+
+		err := dispatcher.Run(ctx, *startBlock)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, personnelclient.ErrEventStreamEnded) && !errors.Is(err, fabricgateway.ErrPeerUnreachable) {
+			log.Fatalf("failed to deliver events: %v", err)
+		}
+
+		log.Printf("%v, listening again in %s", err, eventRetryDelay)
+		select {
+		case <-time.After(eventRetryDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package contracts
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/chaincode/repository"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxPageSize bounds the documents one admin transaction touches, keeping its read/write set small
+const maxPageSize = 500
+
+// AdminContract holds maintenance transactions restricted to the admin role
+type AdminContract struct {
+	contractapi.Contract
+}
+
+func NewAdminContract() *AdminContract {
+	c := &AdminContract{}
+	c.Contract = newContract("AdminContract", c)
+	return c
+}
+
+func (c *AdminContract) GetEvaluateTransactions() []string {
+	return []string{"VerifyIndexes"}
+}
+
+// adminRepo is implemented by every repository through its embedded generic repo
+type adminRepo interface {
+	DocType() string
+	Key(id string) string
+	Migrate(fromVersion, pageSize int, bookmark string) (*repository.MigrationPage, error)
+	OwnsBookmark(bookmark string) bool
+	VerifyIndexes(pageSize int, bookmark string) (*repository.IndexPage, error)
+	RepairIndexes(pageSize int, bookmark string) (*repository.IndexPage, error)
+}
+
+// adminRepos lists the repositories in key order, so a bookmark moves forward through them
+func adminRepos(stub shim.ChaincodeStubInterface) []adminRepo {
+	return []adminRepo{
+		repository.NewCourseRepo(stub),
+		repository.NewPersonnelRepo(stub),
+		repository.NewTrainingRepo(stub),
+	}
+}
+
+// MigrateState rewrites documents stored at fromVersion with the current schema version. At most
+// pageSize documents are scanned per call, pass the returned bookmark back in until done is true.
+func (c *AdminContract) MigrateState(ctx contractapi.TransactionContextInterface, fromVersion, pageSize int, bookmark string) (*domain.MigrationResult, error) {
+	if fromVersion < 0 {
+		return nil, fmt.Errorf("fromVersion must not be negative")
+	}
+	if pageSize < 1 || pageSize > maxPageSize {
+		return nil, fmt.Errorf("pageSize must be between 1 and %d", maxPageSize)
+	}
+
+	repos := adminRepos(ctx.GetStub())
+
+	start := 0
+	if bookmark != "" {
+		start = -1
+		for i, repo := range repos {
+			if strings.HasPrefix(bookmark, repo.Key("")) {
+				start = i
+				break
+			}
+		}
+		if start == -1 {
+			return nil, fmt.Errorf("invalid bookmark [%s]", bookmark)
+		}
+	}
+
+	result := &domain.MigrationResult{
+		FromVersion: fromVersion,
+	}
+
+	remaining := pageSize
+	for i := start; i < len(repos); i++ {
+		page, err := repos[i].Migrate(fromVersion, remaining, bookmark)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate %s documents: %w", repos[i].DocType(), err)
+		}
+		bookmark = ""
+
+		result.Scanned += page.Scanned
+		result.Migrated += page.Migrated
+		remaining -= page.Scanned
+
+		if page.Bookmark != "" {
+			result.Bookmark = page.Bookmark
+			return result, nil
+		}
+		if remaining == 0 && i+1 < len(repos) {
+			result.Bookmark = repos[i+1].Key("")
+			return result, nil
+		}
+	}
+
+	result.Done = true
+
+	return result, nil
+}
+
+// VerifyIndexes reports index entries without a matching document and documents missing index
+// entries. At most pageSize documents and index entries are scanned per call, pass the returned
+// bookmark back in until done is true.
+func (c *AdminContract) VerifyIndexes(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*domain.IndexReport, error) {
+	report := &domain.IndexReport{
+		Orphaned: []domain.IndexProblem{},
+		Missing:  []domain.IndexProblem{},
+	}
+
+	verify := func(repo adminRepo, pageSize int, bookmark string) (*repository.IndexPage, error) {
+		return repo.VerifyIndexes(pageSize, bookmark)
+	}
+	nextBookmark, err := scanIndexPages(ctx.GetStub(), pageSize, bookmark, verify, func(page *repository.IndexPage) {
+		report.Documents += page.Documents
+		report.IndexEntries += page.IndexEntries
+		report.Orphaned = append(report.Orphaned, page.Orphaned...)
+		report.Missing = append(report.Missing, page.Missing...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify indexes: %w", err)
+	}
+
+	report.Consistent = len(report.Orphaned) == 0 && len(report.Missing) == 0
+	report.Bookmark = nextBookmark
+	report.Done = nextBookmark == ""
+
+	return report, nil
+}
+
+// RepairIndexes adds missing index entries and removes orphaned ones. At most pageSize documents and
+// index entries are scanned per call, pass the returned bookmark back in until done is true.
+func (c *AdminContract) RepairIndexes(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*domain.IndexRepairResult, error) {
+	result := &domain.IndexRepairResult{}
+
+	repair := func(repo adminRepo, pageSize int, bookmark string) (*repository.IndexPage, error) {
+		return repo.RepairIndexes(pageSize, bookmark)
+	}
+	nextBookmark, err := scanIndexPages(ctx.GetStub(), pageSize, bookmark, repair, func(page *repository.IndexPage) {
+		result.Scanned += page.Scanned
+		result.Added += len(page.Missing)
+		result.Removed += len(page.Orphaned)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to repair indexes: %w", err)
+	}
+
+	result.Bookmark = nextBookmark
+	result.Done = nextBookmark == ""
+
+	return result, nil
+}
+
+// scanIndexPages runs scan over the repositories from the bookmark until pageSize documents and
+// index entries have been scanned, passing each repository's page to collect. It returns the
+// bookmark to resume from, empty once every repository is done.
+func scanIndexPages(stub shim.ChaincodeStubInterface, pageSize int, bookmark string, scan func(adminRepo, int, string) (*repository.IndexPage, error), collect func(*repository.IndexPage)) (string, error) {
+	if pageSize < 1 || pageSize > maxPageSize {
+		return "", fmt.Errorf("pageSize must be between 1 and %d", maxPageSize)
+	}
+
+	repos := adminRepos(stub)
+
+	start := 0
+	if bookmark != "" {
+		start = slices.IndexFunc(repos, func(repo adminRepo) bool { return repo.OwnsBookmark(bookmark) })
+		if start == -1 {
+			return "", fmt.Errorf("invalid bookmark [%s]", bookmark)
+		}
+	}
+
+	remaining := pageSize
+	for i := start; i < len(repos); i++ {
+		page, err := scan(repos[i], remaining, bookmark)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", repos[i].DocType(), err)
+		}
+		bookmark = ""
+
+		collect(page)
+		remaining -= page.Scanned
+
+		if page.Bookmark != "" {
+			return page.Bookmark, nil
+		}
+		if remaining == 0 && i+1 < len(repos) {
+			return repos[i+1].Key(""), nil
+		}
+	}
+
+	return "", nil
+}
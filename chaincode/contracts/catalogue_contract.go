@@ -0,0 +1,76 @@
+package contracts
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/chaincode/repository"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// CatalogueContract holds the courses that training codes refer to
+type CatalogueContract struct {
+	contractapi.Contract
+}
+
+func NewCatalogueContract() *CatalogueContract {
+	c := &CatalogueContract{}
+	c.Contract = newContract("CatalogueContract", c)
+	return c
+}
+
+func (c *CatalogueContract) GetEvaluateTransactions() []string {
+	return []string{"GetCourse", "ListCourses"}
+}
+
+func (c *CatalogueContract) AddCourse(ctx contractapi.TransactionContextInterface, trainingCode, title, campus, description string) (*domain.Course, error) {
+	if trainingCode == "" {
+		return nil, fmt.Errorf("trainingCode is required")
+	}
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	if campus == "" {
+		return nil, fmt.Errorf("campus is required")
+	}
+
+	repo := repository.NewCourseRepo(ctx.GetStub())
+
+	exists, err := repo.Exists(trainingCode)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("course with code %s already exists", trainingCode)
+	}
+
+	course := &domain.Course{
+		TrainingCode: trainingCode,
+		Title:        title,
+		Campus:       campus,
+		Description:  description,
+	}
+
+	if err := repo.Put(course); err != nil {
+		return nil, err
+	}
+
+	return course, nil
+}
+
+func (c *CatalogueContract) GetCourse(ctx contractapi.TransactionContextInterface, trainingCode string) (*domain.Course, error) {
+	course, err := repository.NewCourseRepo(ctx.GetStub()).Get(trainingCode)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("course with code %s does not exist", trainingCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return course, nil
+}
+
+func (c *CatalogueContract) ListCourses(ctx contractapi.TransactionContextInterface) ([]*domain.Course, error) {
+	return repository.NewCourseRepo(ctx.GetStub()).List()
+}
@@ -0,0 +1,201 @@
+package contracts
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RoleAttribute is the certificate attribute carrying a caller's academy role
+const RoleAttribute = "starfleet.role"
+
+const RoleAdmin = "admin"
+
+// TransactionContext is the context passed to every contract function, carrying what the
+// before hook resolved about the caller so the after hook and functions can use it
+type TransactionContext struct {
+	contractapi.TransactionContext
+
+	function  string
+	callerMSP string
+	startedAt time.Time
+}
+
+// transactionRoles lists the roles allowed to call each function, the admin role may call anything.
+// Functions not listed are open to any member of the channel.
+var transactionRoles = map[string][]string{
+	"MigrateState":  {},
+	"VerifyIndexes": {},
+	"RepairIndexes": {},
+}
+
+// beforeTransaction resolves the caller, enforces transactionRoles and logs the arguments
+func beforeTransaction(ctx *TransactionContext) error {
+	ctx.startedAt = time.Now()
+
+	function, params := ctx.GetStub().GetFunctionAndParameters()
+	ctx.function = function
+
+	if ctx.GetClientIdentity() == nil {
+		return fmt.Errorf("failed to read caller identity")
+	}
+
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to read caller MSP ID: %w", err)
+	}
+	ctx.callerMSP = mspID
+
+	if err := checkAccess(ctx, function); err != nil {
+		return err
+	}
+
+	ctx.logger().Debug("transaction arguments", slog.Any("args", params))
+
+	return nil
+}
+
+// afterTransaction logs the time taken by a successful transaction
+func afterTransaction(ctx *TransactionContext, _ interface{}) error {
+	ctx.logger().Debug("transaction finished", slog.String("duration", time.Since(ctx.startedAt).String()))
+	return nil
+}
+
+// checkAccess rejects callers without one of the roles required by the function
+func checkAccess(ctx *TransactionContext, function string) error {
+	// Rules are keyed by the bare function name so they apply whichever contract name prefix is used
+	name := function[strings.LastIndex(function, ":")+1:]
+
+	roles, restricted := transactionRoles[name]
+	if !restricted {
+		return nil
+	}
+
+	role, found, err := ctx.GetClientIdentity().GetAttributeValue(RoleAttribute)
+	if err != nil {
+		return fmt.Errorf("failed to read caller role: %w", err)
+	}
+	if found && (role == RoleAdmin || slices.Contains(roles, role)) {
+		return nil
+	}
+
+	allowed := append(slices.Clone(roles), RoleAdmin)
+	return fmt.Errorf("access denied: %s requires %s attribute [%s] (caller %s has [%s])", name, RoleAttribute, strings.Join(allowed, "|"), ctx.callerMSP, role)
+}
+
+func (ctx *TransactionContext) logger() *slog.Logger {
+	return slog.Default().With(
+		slog.String("tx_id", ctx.GetStub().GetTxID()),
+		slog.String("function", ctx.function),
+		slog.String("caller_msp", ctx.callerMSP),
+	)
+}
+
+// unknownTransactionHandler returns an unknown-function hook that lists the functions the contract
+// does provide, suggesting the closest match for a likely typo
+func unknownTransactionHandler(contract contractapi.ContractInterface) func(ctx *TransactionContext) error {
+	valid := transactionNames(contract)
+
+	return func(ctx *TransactionContext) error {
+		function, _ := ctx.GetStub().GetFunctionAndParameters()
+		name := function[strings.LastIndex(function, ":")+1:]
+
+		message := fmt.Sprintf("function [%s] not found in contract %s, valid functions are: %s", name, contract.GetName(), strings.Join(valid, ", "))
+		if suggestion := closestMatch(name, valid); suggestion != "" {
+			message += fmt.Sprintf(" (did you mean %s:%s?)", contract.GetName(), suggestion)
+		}
+
+		return fmt.Errorf("%s", message)
+	}
+}
+
+// transactionNames lists the exported methods contractapi exposes as transactions for the contract
+func transactionNames(contract contractapi.ContractInterface) []string {
+	excluded := map[string]bool{}
+	base := reflect.TypeOf(&contractapi.Contract{})
+	for i := 0; i < base.NumMethod(); i++ {
+		excluded[base.Method(i).Name] = true
+	}
+	if ignorer, ok := contract.(contractapi.IgnoreContractInterface); ok {
+		for _, name := range ignorer.GetIgnoredFunctions() {
+			excluded[name] = true
+		}
+	}
+	excluded["GetIgnoredFunctions"] = true
+	excluded["GetEvaluateTransactions"] = true
+
+	var names []string
+	contractType := reflect.TypeOf(contract)
+	for i := 0; i < contractType.NumMethod(); i++ {
+		name := contractType.Method(i).Name
+		if !excluded[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// FunctionNames lists every function name the chaincode routes for the given contracts: each
+// transaction prefixed with its contract name, bare names for the first (default) contract, and the
+// system metadata query
+func FunctionNames(contracts ...contractapi.ContractInterface) []string {
+	names := []string{contractapi.SystemContractName + ":GetMetadata"}
+
+	for i, contract := range contracts {
+		for _, name := range transactionNames(contract) {
+			names = append(names, contract.GetName()+":"+name)
+			if i == 0 {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// closestMatch returns the candidate within a small edit distance of name, ignoring case
+func closestMatch(name string, candidates []string) string {
+	best := ""
+	bestDistance := 3 // anything further away is unlikely to be a typo
+
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
@@ -1,10 +1,12 @@
 package contracts
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/chaincode/repository"
 	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -13,241 +15,223 @@ type PersonnelContract struct {
 	contractapi.Contract
 }
 
-func (c *PersonnelContract) Name() string {
-	return "PersonnelContract"
+func NewPersonnelContract() *PersonnelContract {
+	c := &PersonnelContract{}
+	c.Contract = newContract("PersonnelContract", c)
+	return c
 }
 
-const (
-	DocTypePersonnel = "personnel"
-	DocTypeTraining  = "training"
-)
-
-func personnelKey(personnelID string) string {
-	return fmt.Sprintf("%s:%s", DocTypePersonnel, personnelID)
-}
-
-func trainingKey(recordID string) string {
-	return fmt.Sprintf("%s:%s", DocTypeTraining, recordID)
+func (c *PersonnelContract) GetEvaluateTransactions() []string {
+	return []string{"GetPersonnel", "GetTranscript"}
 }
 
 func (c *PersonnelContract) GetPersonnel(ctx contractapi.TransactionContextInterface, personnelID string) (*domain.Personnel, error) {
-	key := personnelKey(personnelID)
+	return getPersonnel(ctx, personnelID)
+}
 
-	personnelBytes, err := ctx.GetStub().GetState(key)
+// GetTranscript assembles the personnel's record, rank and status changes, and training with course
+// titles. The transaction IDs come from the key history, which the peer must have enabled.
+func (c *PersonnelContract) GetTranscript(ctx contractapi.TransactionContextInterface, personnelID string) (*domain.Transcript, error) {
+	personnel, err := getPersonnel(ctx, personnelID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read personnel from world state: %w", err)
-	}
-	if personnelBytes == nil {
-		return nil, fmt.Errorf("personnel with ID %s does not exist", personnelID)
+		return nil, err
 	}
 
-	var personnel *domain.Personnel
-	if err := json.Unmarshal(personnelBytes, &personnel); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal personnel data: %w", err)
-	}
-
-	return personnel, nil
-}
+	stub := ctx.GetStub()
+	trainingRepo := repository.NewTrainingRepo(stub)
+	courseRepo := repository.NewCourseRepo(stub)
 
-func (c *PersonnelContract) EnrollCadet(ctx contractapi.TransactionContextInterface, personnelID, name, campus string) (*domain.Personnel, error) {
-	if personnelID == "" {
-		return nil, fmt.Errorf("personnelID is required")
-	}
-	if name == "" {
-		return nil, fmt.Errorf("name is required")
-	}
-	if campus == "" {
-		return nil, fmt.Errorf("campus is required")
+	revisions, err := repository.NewPersonnelRepo(stub).History(personnelID)
+	if err != nil {
+		return nil, err
 	}
 
-	existingPersonnel, err := c.GetPersonnel(ctx, personnelID)
-	if err == nil && existingPersonnel != nil {
-		return nil, fmt.Errorf("personnel with ID %s already exists", personnelID)
+	transcript := &domain.Transcript{
+		Personnel:     personnel,
+		StatusChanges: []domain.StatusChange{},
+		Training:      []domain.TranscriptLine{},
 	}
 
-	personnel := &domain.Personnel{
-		PersonnelID: personnelID,
-		Name:        name,
-		Rank:        domain.PersonnelRankCadet,
-		Campus:      campus,
-		Status:      domain.PersonnelStatusActive,
+	var previous *domain.Personnel
+	for _, revision := range revisions {
+		transcript.TransactionID = revision.TxID
+
+		current := revision.Doc
+		if current != nil && (previous == nil || current.Rank != previous.Rank || current.Status != previous.Status) {
+			transcript.StatusChanges = append(transcript.StatusChanges, domain.StatusChange{
+				Rank:          current.Rank,
+				Status:        current.Status,
+				ChangedAt:     revision.Timestamp.UTC().Format(time.RFC3339),
+				TransactionID: revision.TxID,
+			})
+		}
+		previous = current
 	}
 
-	personnelBytes, err := json.Marshal(personnel)
+	records, err := trainingRepo.ListByPersonnel(personnelID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal personnel: %v", err)
-	}
+		return nil, err
+	}
+
+	titles := map[string]string{}
+	for _, record := range records {
+		title, known := titles[record.TrainingCode]
+		if !known {
+			course, err := courseRepo.Get(record.TrainingCode)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return nil, err
+			}
+			if course != nil {
+				title = course.Title
+			}
+			titles[record.TrainingCode] = title
+		}
+
+		recordRevisions, err := trainingRepo.History(record.RecordID)
+		if err != nil {
+			return nil, err
+		}
 
-	if err := ctx.GetStub().PutState(personnelKey(personnelID), personnelBytes); err != nil {
-		return nil, fmt.Errorf("failed to put personnel state: %v", err)
+		var txID string
+		if len(recordRevisions) > 0 {
+			txID = recordRevisions[len(recordRevisions)-1].TxID
+		}
+
+		transcript.Training = append(transcript.Training, domain.TranscriptLine{
+			RecordID:      record.RecordID,
+			TrainingCode:  record.TrainingCode,
+			CourseTitle:   title,
+			Campus:        record.Campus,
+			CompletedAt:   record.CompletedAt,
+			IssuedBy:      record.IssuedBy,
+			Status:        record.Status,
+			TransactionID: txID,
+		})
 	}
 
-	return personnel, nil
+	return transcript, nil
 }
 
-func (c *PersonnelContract) CompleteTraining(ctx contractapi.TransactionContextInterface, recordID, personnelID, campus, trainingCode, completedAt, issuedBy string) (*domain.Training, error) {
-	// Parameter validation
-	if recordID == "" {
-		return nil, fmt.Errorf("recordID is required")
-	}
-	if personnelID == "" {
-		return nil, fmt.Errorf("personnelID is required")
-	}
-	if campus == "" {
-		return nil, fmt.Errorf("campus is required")
-	}
-	if trainingCode == "" {
-		return nil, fmt.Errorf("trainingCode is required")
-	}
-	if completedAt == "" {
-		return nil, fmt.Errorf("completedAt is required")
-	}
-	if issuedBy == "" {
-		return nil, fmt.Errorf("issuedBy is required")
+func (c *PersonnelContract) EnrollCadet(ctx contractapi.TransactionContextInterface, personnelID, name, campus string) (*domain.Personnel, error) {
+	if err := validateEnrolment(personnelID, name, campus); err != nil {
+		return nil, err
 	}
 
-	// Check for valid completedAt format (ISO 8601)
-	if _, err := time.Parse(time.RFC3339, completedAt); err != nil {
-		return nil, fmt.Errorf("completedAt must be in ISO 8601 / RFC3339 format: %w", err)
-	}
+	repo := repository.NewPersonnelRepo(ctx.GetStub())
 
-	// Existing record check
-	existingTraining, err := ctx.GetStub().GetState(trainingKey(recordID))
+	exists, err := repo.Exists(personnelID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check existing training state: %w", err)
+		return nil, err
 	}
-	if existingTraining != nil {
-		return nil, fmt.Errorf("training record with ID [%s] already exists", recordID)
+	if exists {
+		return nil, fmt.Errorf("personnel with ID %s already exists", personnelID)
 	}
 
-	personnel, err := c.GetPersonnel(ctx, personnelID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get personnel: %w", err)
-	}
+	personnel := newCadet(personnelID, name, campus)
 
-	if personnel.Status != domain.PersonnelStatusActive {
-		return nil, fmt.Errorf("cannot complete training for personnel with status [%s]", personnel.Status)
+	if err := repo.Put(personnel); err != nil {
+		return nil, err
 	}
 
-	if personnel.Campus != campus {
-		return nil, fmt.Errorf("personnel is not enrolled in campus [%s] (current campus [%s])", campus, personnel.Campus)
+	if err := setEvent(ctx, domain.EventCadetEnrolled, personnel); err != nil {
+		return nil, err
 	}
 
-	hasTraining, err := c.personnelHasTraining(ctx, personnelID, trainingCode)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check existing training: %w", err)
+	return personnel, nil
+}
+
+// EnrollCadetsBatch enrols every cadet or none of them. All entries are validated, including for
+// duplicate IDs within the batch and against the ledger, before any is written.
+func (c *PersonnelContract) EnrollCadetsBatch(ctx contractapi.TransactionContextInterface, cadets []domain.CadetEnrolment) (*domain.BatchResult, error) {
+	if len(cadets) == 0 {
+		return nil, fmt.Errorf("at least one cadet is required")
 	}
-	if hasTraining {
-		return nil, fmt.Errorf("personnel has already completed training with code [%s]", trainingCode)
+	if len(cadets) > maxBatchSize {
+		return nil, fmt.Errorf("a batch may hold at most %d cadets, got %d", maxBatchSize, len(cadets))
 	}
 
-	training := &domain.Training{
-		RecordID:     recordID,
-		PersonnelID:  personnelID,
-		Campus:       campus,
-		TrainingCode: trainingCode,
-		CompletedAt:  completedAt,
-		IssuedBy:     issuedBy,
-		Status:       domain.TrainingStatusCompleted,
-	}
+	repo := repository.NewPersonnelRepo(ctx.GetStub())
 
-	// Store primary record
-	trainingBytes, err := json.Marshal(training)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal training: %w", err)
+	result := &domain.BatchResult{
+		Committed: true,
+		Results:   make([]domain.BatchEntryResult, len(cadets)),
 	}
 
-	if err := ctx.GetStub().PutState(trainingKey(recordID), trainingBytes); err != nil {
-		return nil, fmt.Errorf("failed to put training state: %w", err)
-	}
+	// Writes are not visible to reads in the same transaction, so duplicates within the batch are tracked here
+	seen := map[string]int{}
+	for i, cadet := range cadets {
+		err := validateEnrolment(cadet.PersonnelID, cadet.Name, cadet.Campus)
+		if err == nil {
+			if first, duplicate := seen[cadet.PersonnelID]; duplicate {
+				err = fmt.Errorf("personnel ID %s is duplicated in the batch (first at entry %d)", cadet.PersonnelID, first)
+			}
+		}
+		if err == nil {
+			var exists bool
+			exists, err = repo.Exists(cadet.PersonnelID)
+			if err == nil && exists {
+				err = fmt.Errorf("personnel with ID %s already exists", cadet.PersonnelID)
+			}
+		}
+		if _, duplicate := seen[cadet.PersonnelID]; !duplicate {
+			seen[cadet.PersonnelID] = i
+		}
 
-	// Composite index: by personnel (timeline)
-	// Pattern `training_byPersonnel~SF-12345~2024-01-01T12:00:00Z~TR-987`
-	// Allows "All training for this personnel", "Ordered history"
-	byPersonnelKey, err := ctx.GetStub().CreateCompositeKey(
-		"training_byPersonnel",
-		[]string{personnelID, completedAt, recordID},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create composite key byPersonnel: %w", err)
+		result.Results[i] = batchEntry(i, cadet.PersonnelID, err)
+		if err != nil {
+			result.Committed = false
+		}
 	}
 
-	if err := ctx.GetStub().PutState(byPersonnelKey, []byte{0x00}); err != nil {
-		return nil, fmt.Errorf("failed to put state for composite key byPersonnel: %w", err)
+	if !result.Committed {
+		skipValidEntries(result)
+		return result, nil
 	}
 
-	// Composite index: byTrainingCode (qualification checks)
-	// Pattern `training_byCode~ENG-WARP-201~SF-12345~TR-987`
-	// Allows "Who has completed ENG-WARP-201?", "Promotion validation"
-	byCodeKey, err := ctx.GetStub().CreateCompositeKey(
-		"training_byCode",
-		[]string{trainingCode, personnelID, recordID},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create composite key byCode: %w", err)
+	enrolled := make([]*domain.Personnel, len(cadets))
+	for i, cadet := range cadets {
+		enrolled[i] = newCadet(cadet.PersonnelID, cadet.Name, cadet.Campus)
+		if err := repo.Put(enrolled[i]); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := ctx.GetStub().PutState(byCodeKey, []byte{0x00}); err != nil {
-		return nil, fmt.Errorf("failed to put state for composite key byCode: %w", err)
+	if err := setEvent(ctx, domain.EventCadetsEnrolled, enrolled); err != nil {
+		return nil, err
 	}
 
-	return training, nil
+	return result, nil
 }
 
-func (c *PersonnelContract) personnelHasTraining(ctx contractapi.TransactionContextInterface, personnelID, trainingCode string) (bool, error) {
-	training, err := c.getTrainingByCodeForPersonnel(ctx, trainingCode, personnelID)
-	if err != nil {
-		return false, err
+func validateEnrolment(personnelID, name, campus string) error {
+	if personnelID == "" {
+		return fmt.Errorf("personnelID is required")
+	}
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if campus == "" {
+		return fmt.Errorf("campus is required")
 	}
-	return training != nil, nil
+	return nil
 }
 
-func (c *PersonnelContract) getTrainingByCodeForPersonnel(ctx contractapi.TransactionContextInterface, trainingCode, personnelID string) (*domain.Training, error) {
-	// Query composite index for this personnel and training code
-	// Pattern `training_byCode~ENG-WARP-201~SF-12345~TR-987`
-	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(
-		"training_byCode",
-		[]string{trainingCode, personnelID},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query composite key byCode: %w", err)
+func newCadet(personnelID, name, campus string) *domain.Personnel {
+	return &domain.Personnel{
+		PersonnelID: personnelID,
+		Name:        name,
+		Rank:        domain.PersonnelRankCadet,
+		Campus:      campus,
+		Status:      domain.PersonnelStatusActive,
 	}
-	defer iterator.Close()
-
-	for iterator.HasNext() {
-		response, err := iterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate composite key byCode: %w", err)
-		}
-
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(response.Key)
-		if err != nil {
-			return nil, fmt.Errorf("failed to split composite key byCode: %w", err)
-		}
-
-		if len(compositeKeyParts) != 3 {
-			continue // skip invalid keys
-		}
-
-		recordID := compositeKeyParts[2]
-
-		trainingBytes, err := ctx.GetStub().GetState(trainingKey(recordID))
-		if err != nil {
-			return nil, fmt.Errorf("failed to get training state: %w", err)
-		}
-		if trainingBytes == nil {
-			continue // skip if training record is missing
-		}
-
-		var training domain.Training
-		if err := json.Unmarshal(trainingBytes, &training); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal training data: %w", err)
-		}
+}
 
-		if training.Status == domain.TrainingStatusCompleted {
-			return &training, nil
-		}
-	}
+// CompleteTraining is a deprecated alias of TrainingContract:CompleteTraining, kept so existing
+// callers of PersonnelContract:CompleteTraining do not break
+func (c *PersonnelContract) CompleteTraining(ctx contractapi.TransactionContextInterface, recordID, personnelID, campus, trainingCode, completedAt, issuedBy string) (*domain.Training, error) {
+	slog.Warn("PersonnelContract:CompleteTraining is deprecated, use TrainingContract:CompleteTraining",
+		slog.String("tx_id", ctx.GetStub().GetTxID()),
+	)
 
-	return nil, nil
+	return completeTraining(ctx, recordID, personnelID, campus, trainingCode, completedAt, issuedBy)
 }
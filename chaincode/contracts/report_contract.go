@@ -0,0 +1,181 @@
+package contracts
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/chaincode/repository"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ReportContract holds read-only queries for academy administrators. Reports without a filter read
+// a whole document type, so are only meant to be evaluated.
+type ReportContract struct {
+	contractapi.Contract
+}
+
+func NewReportContract() *ReportContract {
+	c := &ReportContract{}
+	c.Contract = newContract("ReportContract", c)
+	return c
+}
+
+func (c *ReportContract) GetEvaluateTransactions() []string {
+	return []string{"GetRoster", "GetCompletionStats", "GetRankDistribution"}
+}
+
+// GetRoster returns the campus's personnel ordered by ID
+func (c *ReportContract) GetRoster(ctx contractapi.TransactionContextInterface, campus string) ([]*domain.Personnel, error) {
+	if campus == "" {
+		return nil, fmt.Errorf("campus is required")
+	}
+
+	return repository.NewPersonnelRepo(ctx.GetStub()).ListByCampus(campus)
+}
+
+// GetCompletionStats counts completed training by code and campus. Every filter is optional, from
+// and to are RFC3339 and bound completedAt inclusively.
+func (c *ReportContract) GetCompletionStats(ctx contractapi.TransactionContextInterface, trainingCode, from, to string) (*domain.CompletionReport, error) {
+	fromTime, err := parseBound("from", from)
+	if err != nil {
+		return nil, err
+	}
+	toTime, err := parseBound("to", to)
+	if err != nil {
+		return nil, err
+	}
+	if !fromTime.IsZero() && !toTime.IsZero() && toTime.Before(fromTime) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+
+	repo := repository.NewTrainingRepo(ctx.GetStub())
+
+	var records []*domain.Training
+	if trainingCode != "" {
+		records, err = repo.ListByCode(trainingCode)
+	} else {
+		records, err = repo.List()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type group struct {
+		trainingCode string
+		campus       string
+	}
+	counts := map[group]*domain.CompletionCount{}
+	groupPersonnel := map[group]map[string]bool{}
+	personnel := map[string]bool{}
+
+	report := &domain.CompletionReport{
+		TrainingCode: trainingCode,
+		From:         from,
+		To:           to,
+		Counts:       []domain.CompletionCount{},
+	}
+
+	for _, record := range records {
+		if record.Status != domain.TrainingStatusCompleted {
+			continue
+		}
+
+		completedAt, err := time.Parse(time.RFC3339, record.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("training record %s has an invalid completedAt: %w", record.RecordID, err)
+		}
+		if (!fromTime.IsZero() && completedAt.Before(fromTime)) || (!toTime.IsZero() && completedAt.After(toTime)) {
+			continue
+		}
+
+		key := group{record.TrainingCode, record.Campus}
+		count, found := counts[key]
+		if !found {
+			count = &domain.CompletionCount{TrainingCode: record.TrainingCode, Campus: record.Campus}
+			counts[key] = count
+			groupPersonnel[key] = map[string]bool{}
+		}
+
+		count.Completions++
+		if !groupPersonnel[key][record.PersonnelID] {
+			groupPersonnel[key][record.PersonnelID] = true
+			count.Personnel++
+		}
+
+		report.Completions++
+		personnel[record.PersonnelID] = true
+	}
+
+	report.Personnel = len(personnel)
+	for _, count := range counts {
+		report.Counts = append(report.Counts, *count)
+	}
+	sort.Slice(report.Counts, func(i, j int) bool {
+		if report.Counts[i].TrainingCode != report.Counts[j].TrainingCode {
+			return report.Counts[i].TrainingCode < report.Counts[j].TrainingCode
+		}
+		return report.Counts[i].Campus < report.Counts[j].Campus
+	})
+
+	return report, nil
+}
+
+// GetRankDistribution counts personnel by rank, for one campus or the whole academy when campus is empty
+func (c *ReportContract) GetRankDistribution(ctx contractapi.TransactionContextInterface, campus string) (*domain.RankDistribution, error) {
+	repo := repository.NewPersonnelRepo(ctx.GetStub())
+
+	var personnel []*domain.Personnel
+	var err error
+	if campus != "" {
+		personnel, err = repo.ListByCampus(campus)
+	} else {
+		personnel, err = repo.List()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	distribution := &domain.RankDistribution{
+		Campus: campus,
+		Total:  len(personnel),
+		Ranks:  []domain.RankCount{},
+	}
+
+	counts := map[string]*domain.RankCount{}
+	for _, member := range personnel {
+		count, found := counts[member.Rank]
+		if !found {
+			count = &domain.RankCount{Rank: member.Rank}
+			counts[member.Rank] = count
+		}
+
+		count.Total++
+		if member.Status == domain.PersonnelStatusActive {
+			count.Active++
+		}
+	}
+
+	for _, count := range counts {
+		distribution.Ranks = append(distribution.Ranks, *count)
+	}
+	sort.Slice(distribution.Ranks, func(i, j int) bool {
+		return distribution.Ranks[i].Rank < distribution.Ranks[j].Rank
+	})
+
+	return distribution, nil
+}
+
+// parseBound reads an optional RFC3339 report bound, the zero time when empty
+func parseBound(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	bound, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be in ISO 8601 / RFC3339 format: %w", name, err)
+	}
+	return bound, nil
+}
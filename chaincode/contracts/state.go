@@ -0,0 +1,68 @@
+package contracts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/chaincode/repository"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxBatchSize bounds the entries of one batch transaction, keeping its read/write set small
+const maxBatchSize = 500
+
+// newContract builds the contractapi settings shared by every contract in the chaincode
+func newContract(name string, contract contractapi.ContractInterface) contractapi.Contract {
+	return contractapi.Contract{
+		Name:                      name,
+		TransactionContextHandler: new(TransactionContext),
+		BeforeTransaction:         beforeTransaction,
+		AfterTransaction:          afterTransaction,
+		UnknownTransaction:        unknownTransactionHandler(contract),
+	}
+}
+
+func getPersonnel(ctx contractapi.TransactionContextInterface, personnelID string) (*domain.Personnel, error) {
+	personnel, err := repository.NewPersonnelRepo(ctx.GetStub()).Get(personnelID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("personnel with ID %s does not exist", personnelID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return personnel, nil
+}
+
+// batchEntry reports a batch entry as written, or rejected with err
+func batchEntry(index int, id string, err error) domain.BatchEntryResult {
+	if err != nil {
+		return domain.BatchEntryResult{Index: index, ID: id, Status: domain.BatchEntryRejected, Error: err.Error()}
+	}
+	return domain.BatchEntryResult{Index: index, ID: id, Status: domain.BatchEntryWritten}
+}
+
+// skipValidEntries marks the entries that passed validation as skipped once the batch is rejected
+func skipValidEntries(result *domain.BatchResult) {
+	for i := range result.Results {
+		if result.Results[i].Status == domain.BatchEntryWritten {
+			result.Results[i].Status = domain.BatchEntrySkipped
+		}
+	}
+}
+
+// setEvent emits the chaincode event for the transaction, replacing any set earlier in it
+func setEvent(ctx contractapi.TransactionContextInterface, name string, payload any) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", name, err)
+	}
+
+	if err := ctx.GetStub().SetEvent(name, payloadBytes); err != nil {
+		return fmt.Errorf("failed to set %s event: %w", name, err)
+	}
+
+	return nil
+}
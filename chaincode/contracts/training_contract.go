@@ -0,0 +1,229 @@
+package contracts
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/chaincode/repository"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+type TrainingContract struct {
+	contractapi.Contract
+}
+
+func NewTrainingContract() *TrainingContract {
+	c := &TrainingContract{}
+	c.Contract = newContract("TrainingContract", c)
+	return c
+}
+
+func (c *TrainingContract) GetEvaluateTransactions() []string {
+	return []string{"GetTraining", "GetTrainingRevision", "GetTrainingHistory", "GetTrainingByCode"}
+}
+
+func (c *TrainingContract) CompleteTraining(ctx contractapi.TransactionContextInterface, recordID, personnelID, campus, trainingCode, completedAt, issuedBy string) (*domain.Training, error) {
+	return completeTraining(ctx, recordID, personnelID, campus, trainingCode, completedAt, issuedBy)
+}
+
+func (c *TrainingContract) GetTraining(ctx contractapi.TransactionContextInterface, recordID string) (*domain.Training, error) {
+	training, err := repository.NewTrainingRepo(ctx.GetStub()).Get(recordID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("training record with ID %s does not exist", recordID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return training, nil
+}
+
+// GetTrainingRevision returns the training record with the transaction that wrote it, read from the
+// peer's history database
+func (c *TrainingContract) GetTrainingRevision(ctx contractapi.TransactionContextInterface, recordID string) (*domain.TrainingRevision, error) {
+	repo := repository.NewTrainingRepo(ctx.GetStub())
+
+	revisions, err := repo.History(recordID)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 || revisions[len(revisions)-1].Doc == nil {
+		return nil, fmt.Errorf("training record with ID %s does not exist", recordID)
+	}
+
+	latest := revisions[len(revisions)-1]
+
+	return &domain.TrainingRevision{
+		Training:      latest.Doc,
+		TransactionID: latest.TxID,
+		Timestamp:     latest.Timestamp.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// GetTrainingHistory returns the personnel's training records ordered by completion time
+func (c *TrainingContract) GetTrainingHistory(ctx contractapi.TransactionContextInterface, personnelID string) ([]*domain.Training, error) {
+	if _, err := getPersonnel(ctx, personnelID); err != nil {
+		return nil, err
+	}
+
+	return repository.NewTrainingRepo(ctx.GetStub()).ListByPersonnel(personnelID)
+}
+
+// GetTrainingByCode returns every training record for the training code
+func (c *TrainingContract) GetTrainingByCode(ctx contractapi.TransactionContextInterface, trainingCode string) ([]*domain.Training, error) {
+	if trainingCode == "" {
+		return nil, fmt.Errorf("trainingCode is required")
+	}
+
+	return repository.NewTrainingRepo(ctx.GetStub()).ListByCode(trainingCode)
+}
+
+// CompleteTrainingBatch records each completion independently, so a rejected entry does not block the
+// rest of a class's results. Every entry is reported as written or rejected with the reason.
+func (c *TrainingContract) CompleteTrainingBatch(ctx contractapi.TransactionContextInterface, completions []domain.TrainingCompletion) (*domain.BatchResult, error) {
+	if len(completions) == 0 {
+		return nil, fmt.Errorf("at least one training completion is required")
+	}
+	if len(completions) > maxBatchSize {
+		return nil, fmt.Errorf("a batch may hold at most %d training completions, got %d", maxBatchSize, len(completions))
+	}
+
+	repo := repository.NewTrainingRepo(ctx.GetStub())
+
+	result := &domain.BatchResult{
+		Committed: true,
+		Results:   make([]domain.BatchEntryResult, len(completions)),
+	}
+
+	var completed []*domain.Training
+
+	// Writes are not visible to reads in the same transaction, so what this batch has written is tracked here
+	writtenRecords := map[string]int{}
+	writtenCodes := map[string]int{}
+	for i, completion := range completions {
+		training := newTraining(completion.RecordID, completion.PersonnelID, completion.Campus, completion.TrainingCode, completion.CompletedAt, completion.IssuedBy)
+		codeKey := training.PersonnelID + "~" + training.TrainingCode
+
+		err := checkTraining(ctx, repo, training)
+		if err == nil {
+			if first, duplicate := writtenRecords[training.RecordID]; duplicate {
+				err = fmt.Errorf("training record with ID [%s] already exists (entry %d)", training.RecordID, first)
+			} else if first, duplicate := writtenCodes[codeKey]; duplicate {
+				err = fmt.Errorf("personnel has already completed training with code [%s] (entry %d)", training.TrainingCode, first)
+			}
+		}
+		if err == nil {
+			if err := repo.Put(training); err != nil {
+				return nil, fmt.Errorf("failed to put training state: %w", err)
+			}
+			writtenRecords[training.RecordID] = i
+			writtenCodes[codeKey] = i
+			completed = append(completed, training)
+		}
+
+		result.Results[i] = batchEntry(i, completion.RecordID, err)
+	}
+
+	if len(completed) > 0 {
+		if err := setEvent(ctx, domain.EventTrainingsCompleted, completed); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// completeTraining is shared by TrainingContract:CompleteTraining and its deprecated PersonnelContract alias
+func completeTraining(ctx contractapi.TransactionContextInterface, recordID, personnelID, campus, trainingCode, completedAt, issuedBy string) (*domain.Training, error) {
+	repo := repository.NewTrainingRepo(ctx.GetStub())
+
+	training := newTraining(recordID, personnelID, campus, trainingCode, completedAt, issuedBy)
+	if err := checkTraining(ctx, repo, training); err != nil {
+		return nil, err
+	}
+
+	// Stores the primary record along with its byPersonnel and byCode index entries
+	if err := repo.Put(training); err != nil {
+		return nil, fmt.Errorf("failed to put training state: %w", err)
+	}
+
+	if err := setEvent(ctx, domain.EventTrainingCompleted, training); err != nil {
+		return nil, err
+	}
+
+	return training, nil
+}
+
+func newTraining(recordID, personnelID, campus, trainingCode, completedAt, issuedBy string) *domain.Training {
+	return &domain.Training{
+		RecordID:     recordID,
+		PersonnelID:  personnelID,
+		Campus:       campus,
+		TrainingCode: trainingCode,
+		CompletedAt:  completedAt,
+		IssuedBy:     issuedBy,
+		Status:       domain.TrainingStatusCompleted,
+	}
+}
+
+// checkTraining validates a new training record against the ledger before it is written
+func checkTraining(ctx contractapi.TransactionContextInterface, repo *repository.TrainingRepo, training *domain.Training) error {
+	// Parameter validation
+	if training.RecordID == "" {
+		return fmt.Errorf("recordID is required")
+	}
+	if training.PersonnelID == "" {
+		return fmt.Errorf("personnelID is required")
+	}
+	if training.Campus == "" {
+		return fmt.Errorf("campus is required")
+	}
+	if training.TrainingCode == "" {
+		return fmt.Errorf("trainingCode is required")
+	}
+	if training.CompletedAt == "" {
+		return fmt.Errorf("completedAt is required")
+	}
+	if training.IssuedBy == "" {
+		return fmt.Errorf("issuedBy is required")
+	}
+
+	// Check for valid completedAt format (ISO 8601)
+	if _, err := time.Parse(time.RFC3339, training.CompletedAt); err != nil {
+		return fmt.Errorf("completedAt must be in ISO 8601 / RFC3339 format: %w", err)
+	}
+
+	// Existing record check
+	exists, err := repo.Exists(training.RecordID)
+	if err != nil {
+		return fmt.Errorf("failed to check existing training state: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("training record with ID [%s] already exists", training.RecordID)
+	}
+
+	personnel, err := getPersonnel(ctx, training.PersonnelID)
+	if err != nil {
+		return fmt.Errorf("failed to get personnel: %w", err)
+	}
+
+	if personnel.Status != domain.PersonnelStatusActive {
+		return fmt.Errorf("cannot complete training for personnel with status [%s]", personnel.Status)
+	}
+
+	if personnel.Campus != training.Campus {
+		return fmt.Errorf("personnel is not enrolled in campus [%s] (current campus [%s])", training.Campus, personnel.Campus)
+	}
+
+	completed, err := repo.FindCompleted(training.PersonnelID, training.TrainingCode)
+	if err != nil {
+		return fmt.Errorf("failed to check existing training: %w", err)
+	}
+	if completed != nil {
+		return fmt.Errorf("personnel has already completed training with code [%s]", training.TrainingCode)
+	}
+
+	return nil
+}
@@ -1,10 +1,18 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/chaincode/contracts"
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/chaincode/monitoring"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -12,56 +20,227 @@ import (
 type serverConfig struct {
 	CCID    string
 	Address string
+
+	TLSDisabled     bool
+	TLSKeyFile      string
+	TLSCertFile     string
+	TLSClientCAFile string
+
+	// MetricsAddress enables the health and metrics side-port when set
+	MetricsAddress string
+
+	// ShutdownTimeout bounds how long in-flight invocations are given to finish on SIGTERM/SIGINT
+	ShutdownTimeout time.Duration
 }
 
 func main() {
-	config := loadConfig()
+	logger, err := monitoring.NewLogger(os.Stderr, os.Getenv("CHAINCODE_LOG_LEVEL"), os.Getenv("CHAINCODE_LOG_FORMAT"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error creating logger: %s\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
 
-	chaincode, err := contractapi.NewChaincode(
-		&contracts.PersonnelContract{},
-	)
+	config, err := loadConfig()
+	if err != nil {
+		fatal(logger, "error loading config", err)
+	}
+	logConfig(logger, config)
 
+	tlsProps, err := getTLSProperties(config)
 	if err != nil {
-		log.Panicf("error creating chaincode: %s", err)
+		fatal(logger, "error loading TLS properties", err)
+	}
+
+	registered := []contractapi.ContractInterface{
+		contracts.NewPersonnelContract(),
+		contracts.NewTrainingContract(),
+		contracts.NewCatalogueContract(),
+		contracts.NewReportContract(),
+		contracts.NewAdminContract(),
+	}
+
+	chaincode, err := contractapi.NewChaincode(registered...)
+
+	if err != nil {
+		fatal(logger, "error creating chaincode", err)
+	}
+
+	metrics := monitoring.NewMetrics()
+	instrumented := monitoring.NewInstrumentedChaincode(chaincode, metrics, logger, contracts.FunctionNames(registered...))
+
+	server, err := newChaincodeServer(config.CCID, config.Address, tlsProps, instrumented)
+	if err != nil {
+		fatal(logger, "error starting chaincode", err)
+	}
+
+	var monitoringServer *monitoring.Server
+	if config.MetricsAddress != "" {
+		monitoringServer = monitoring.NewServer(config.MetricsAddress, metrics, logger)
+		monitoringServer.Start()
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("starting chaincode server", slog.String("address", server.Addr().String()))
+		serverErr <- server.Serve()
+	}()
+
+	if monitoringServer != nil {
+		// The listener is already bound, so the peer can connect as soon as this is reported
+		monitoringServer.SetReady(true)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+	select {
+	case err := <-serverErr:
+		if monitoringServer != nil {
+			monitoringServer.SetReady(false)
+		}
+		fatal(logger, "chaincode server stopped", err)
+	case sig := <-signals:
+		logger.Info("shutting down", slog.String("signal", sig.String()), slog.String("timeout", config.ShutdownTimeout.String()))
+	}
+
+	if monitoringServer != nil {
+		monitoringServer.SetReady(false)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	if err := instrumented.Drain(ctx); err != nil {
+		logger.Warn("in-flight invocations did not finish before the shutdown timeout", slog.Any("error", err))
+	} else {
+		logger.Info("in-flight invocations drained")
+	}
+
+	// Invoke returning does not mean the peer has its response, the server is only stopped once
+	// every response has been sent
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Warn("chaincode server did not flush its responses before the shutdown timeout", slog.Any("error", err))
+	} else {
+		logger.Info("chaincode server stopped")
 	}
 
-	server := &shim.ChaincodeServer{
-		CCID:     config.CCID,
-		Address:  config.Address,
-		CC:       chaincode,
-		TLSProps: getTLSProperties(),
+	if err := <-serverErr; err != nil {
+		logger.Warn("chaincode server stopped with an error", slog.Any("error", err))
 	}
 
-	log.Println("Starting chaincode server...")
-	if err := server.Start(); err != nil {
-		log.Panicf("error starting chaincode: %s", err)
+	if monitoringServer != nil {
+		if err := monitoringServer.Shutdown(ctx); err != nil {
+			logger.Warn("error stopping monitoring server", slog.Any("error", err))
+		}
 	}
 }
 
-func loadConfig() *serverConfig {
+// fatal logs the error and exits, used in place of log.Panicf so the failure is a structured log line
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
+
+func loadConfig() (*serverConfig, error) {
 	ccid := os.Getenv("CHAINCODE_CCID")
 	if ccid == "" {
-		log.Panic("CHAINCODE_CCID environment variable is required")
+		return nil, errors.New("CHAINCODE_CCID environment variable is required")
 	}
 
 	address := os.Getenv("CHAINCODE_ADDRESS")
 	if address == "" {
-		log.Panic("CHAINCODE_ADDRESS environment variable is required")
+		return nil, errors.New("CHAINCODE_ADDRESS environment variable is required")
 	}
 
-	log.Println("=== Config ===")
-	log.Printf("  CHAINCODE_CCID: %s", ccid)
-	log.Printf("  CHAINCODE_ADDRESS: %s", address)
+	tlsDisabled := true
+	if value := os.Getenv("CHAINCODE_TLS_DISABLED"); value != "" {
+		disabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("CHAINCODE_TLS_DISABLED must be true or false: %w", err)
+		}
+		tlsDisabled = disabled
+	}
 
-	return &serverConfig{
+	shutdownTimeout := 30 * time.Second
+	if value := os.Getenv("CHAINCODE_SHUTDOWN_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("CHAINCODE_SHUTDOWN_TIMEOUT must be a duration such as 30s: %w", err)
+		}
+		shutdownTimeout = timeout
+	}
+
+	config := &serverConfig{
 		CCID:    ccid,
 		Address: address,
+
+		TLSDisabled:     tlsDisabled,
+		TLSKeyFile:      os.Getenv("CHAINCODE_TLS_KEY"),
+		TLSCertFile:     os.Getenv("CHAINCODE_TLS_CERT"),
+		TLSClientCAFile: os.Getenv("CHAINCODE_CLIENT_CA_CERT"),
+
+		MetricsAddress: os.Getenv("CHAINCODE_METRICS_ADDRESS"),
+
+		ShutdownTimeout: shutdownTimeout,
 	}
+
+	if !config.TLSDisabled {
+		if config.TLSKeyFile == "" {
+			return nil, errors.New("CHAINCODE_TLS_KEY environment variable is required when TLS is enabled")
+		}
+		if config.TLSCertFile == "" {
+			return nil, errors.New("CHAINCODE_TLS_CERT environment variable is required when TLS is enabled")
+		}
+	}
+
+	return config, nil
 }
 
-func getTLSProperties() shim.TLSProperties {
-	// not tested yet
-	return shim.TLSProperties{
-		Disabled: true,
+func logConfig(logger *slog.Logger, config *serverConfig) {
+	logger.Info("config",
+		slog.String("CHAINCODE_CCID", config.CCID),
+		slog.String("CHAINCODE_ADDRESS", config.Address),
+		slog.Bool("CHAINCODE_TLS_DISABLED", config.TLSDisabled),
+		slog.String("CHAINCODE_TLS_KEY", config.TLSKeyFile),
+		slog.String("CHAINCODE_TLS_CERT", config.TLSCertFile),
+		slog.String("CHAINCODE_CLIENT_CA_CERT", config.TLSClientCAFile),
+		slog.String("CHAINCODE_METRICS_ADDRESS", config.MetricsAddress),
+		slog.String("CHAINCODE_SHUTDOWN_TIMEOUT", config.ShutdownTimeout.String()),
+	)
+}
+
+// getTLSProperties loads the server key pair, and the client CA when set so the peer must present
+// a certificate it issued (mutual TLS)
+func getTLSProperties(config *serverConfig) (shim.TLSProperties, error) {
+	if config.TLSDisabled {
+		return shim.TLSProperties{
+			Disabled: true,
+		}, nil
+	}
+
+	key, err := os.ReadFile(config.TLSKeyFile)
+	if err != nil {
+		return shim.TLSProperties{}, fmt.Errorf("error reading TLS key: %w", err)
 	}
+
+	cert, err := os.ReadFile(config.TLSCertFile)
+	if err != nil {
+		return shim.TLSProperties{}, fmt.Errorf("error reading TLS certificate: %w", err)
+	}
+
+	var clientCACerts []byte
+	if config.TLSClientCAFile != "" {
+		clientCACerts, err = os.ReadFile(config.TLSClientCAFile)
+		if err != nil {
+			return shim.TLSProperties{}, fmt.Errorf("error reading TLS client CA certificate: %w", err)
+		}
+	}
+
+	return shim.TLSProperties{
+		Disabled:      false,
+		Key:           key,
+		Cert:          cert,
+		ClientCACerts: clientCACerts,
+	}, nil
 }
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// testCertificate is a generated key pair, signed by parent or self-signed when parent is nil
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+func newTestCertificate(t *testing.T, commonName string, parent *testCertificate, isCA bool) *testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("failed to generate serial: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+
+	signer, signerCertificate := key, template
+	if parent != nil {
+		signer, signerCertificate = parent.key, parent.certificate
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCertificate, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	return &testCertificate{certificate: certificate, key: key}
+}
+
+func (c *testCertificate) certificatePEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.certificate.Raw})
+}
+
+func (c *testCertificate) keyPEM(t *testing.T) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func (c *testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	pair, err := tls.X509KeyPair(c.certificatePEM(), c.keyPEM(t))
+	if err != nil {
+		t.Fatalf("failed to load key pair: %v", err)
+	}
+	return pair
+}
+
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// noopChaincode is never invoked, the tests only get as far as the shim registering with the peer
+type noopChaincode struct{}
+
+func (noopChaincode) Init(shim.ChaincodeStubInterface) pb.Response   { return shim.Success(nil) }
+func (noopChaincode) Invoke(shim.ChaincodeStubInterface) pb.Response { return shim.Success(nil) }
+
+// startTLSServer starts the chaincode server with mutual TLS, loading the key pairs the way main does
+func startTLSServer(t *testing.T, ca, serverCertificate *testCertificate) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	config := &serverConfig{
+		CCID:            "starfleet-personnel:test",
+		TLSKeyFile:      writeFile(t, dir, "server.key", serverCertificate.keyPEM(t)),
+		TLSCertFile:     writeFile(t, dir, "server.crt", serverCertificate.certificatePEM()),
+		TLSClientCAFile: writeFile(t, dir, "ca.crt", ca.certificatePEM()),
+	}
+
+	tlsProps, err := getTLSProperties(config)
+	if err != nil {
+		t.Fatalf("getTLSProperties: %v", err)
+	}
+
+	server, err := newChaincodeServer(config.CCID, "127.0.0.1:0", tlsProps, noopChaincode{})
+	if err != nil {
+		t.Fatalf("newChaincodeServer: %v", err)
+	}
+	t.Cleanup(server.Stop)
+
+	go server.Serve()
+
+	return server.Addr().String()
+}
+
+// connectAsPeer opens the chaincode stream as a peer would and returns the first message the shim
+// sends, its registration
+func connectAsPeer(t *testing.T, address string, tlsConfig *tls.Config) (*pb.ChaincodeMessage, error) {
+	t.Helper()
+
+	connection, err := grpc.NewClient(address, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer connection.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := pb.NewChaincodeClient(connection).Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stream.Recv()
+}
+
+func TestChaincodeServerMutualTLS(t *testing.T) {
+	ca := newTestCertificate(t, "ca.academy", nil, true)
+	server := newTestCertificate(t, "chaincode.academy", ca, false)
+	peer := newTestCertificate(t, "peer0.academy", ca, false)
+
+	address := startTLSServer(t, ca, server)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+
+	t.Run("client signed by the CA completes the handshake", func(t *testing.T) {
+		message, err := connectAsPeer(t, address, &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "localhost",
+			Certificates: []tls.Certificate{peer.tlsCertificate(t)},
+		})
+		if err != nil {
+			t.Fatalf("expected the connection to succeed, got %v", err)
+		}
+		if message.GetType() != pb.ChaincodeMessage_REGISTER {
+			t.Fatalf("expected the shim to register, got %s", message.GetType())
+		}
+	})
+
+	t.Run("client without a certificate is rejected", func(t *testing.T) {
+		_, err := connectAsPeer(t, address, &tls.Config{
+			RootCAs:    roots,
+			ServerName: "localhost",
+		})
+		if err == nil {
+			t.Fatal("expected the connection to be rejected")
+		}
+	})
+}
+
+// blockingChaincode holds every invocation until release is closed
+type blockingChaincode struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c blockingChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return c.Invoke(stub)
+}
+
+func (c blockingChaincode) Invoke(shim.ChaincodeStubInterface) pb.Response {
+	close(c.started)
+	<-c.release
+	return shim.Success([]byte("done"))
+}
+
+func TestChaincodeServerShutdownSendsResponses(t *testing.T) {
+	chaincode := blockingChaincode{started: make(chan struct{}), release: make(chan struct{})}
+
+	server, err := newChaincodeServer("starfleet-personnel:test", "127.0.0.1:0", shim.TLSProperties{Disabled: true}, chaincode)
+	if err != nil {
+		t.Fatalf("newChaincodeServer: %v", err)
+	}
+	t.Cleanup(server.Stop)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Serve()
+	}()
+
+	connection, err := grpc.NewClient(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer connection.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := pb.NewChaincodeClient(connection).Connect(ctx)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("expected the shim to register: %v", err)
+	}
+
+	input, err := proto.Marshal(protoadapt.MessageV2Of(&pb.ChaincodeInput{Args: [][]byte{[]byte("GetRoster")}}))
+	if err != nil {
+		t.Fatalf("failed to marshal input: %v", err)
+	}
+
+	for _, message := range []*pb.ChaincodeMessage{
+		{Type: pb.ChaincodeMessage_REGISTERED},
+		{Type: pb.ChaincodeMessage_READY},
+		{Type: pb.ChaincodeMessage_TRANSACTION, Txid: "tx1", ChannelId: "academy", Payload: input},
+	} {
+		if err := stream.Send(message); err != nil {
+			t.Fatalf("failed to send %s: %v", message.GetType(), err)
+		}
+	}
+
+	<-chaincode.started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.Shutdown(ctx)
+	}()
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("expected shutdown to wait for the response, returned %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(chaincode.release)
+
+	response, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("expected the response before the stream closed, got %v", err)
+	}
+	if response.GetType() != pb.ChaincodeMessage_COMPLETED || response.GetTxid() != "tx1" {
+		t.Fatalf("expected tx1 to complete, got %s for %s", response.GetType(), response.GetTxid())
+	}
+
+	if err := <-shutdown; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if err := <-serverErr; err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package monitoring
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// unknownFunction labels metrics for invocations of a function the chaincode does not route, so
+// callers cannot grow the label set
+const unknownFunction = "unknown"
+
+// InstrumentedChaincode wraps a chaincode, logging and recording metrics for every Init and Invoke,
+// and tracking in-flight invocations so they can be drained on shutdown
+type InstrumentedChaincode struct {
+	chaincode shim.Chaincode
+	metrics   *Metrics
+	logger    *slog.Logger
+	functions map[string]bool
+
+	mu       sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+}
+
+// NewInstrumentedChaincode wraps chaincode, functions are the names recorded as metric labels, any
+// other function is recorded as "unknown"
+func NewInstrumentedChaincode(chaincode shim.Chaincode, metrics *Metrics, logger *slog.Logger, functions []string) *InstrumentedChaincode {
+	known := make(map[string]bool, len(functions))
+	for _, function := range functions {
+		known[function] = true
+	}
+
+	return &InstrumentedChaincode{
+		chaincode: chaincode,
+		metrics:   metrics,
+		logger:    logger,
+		functions: known,
+	}
+}
+
+func (c *InstrumentedChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return c.instrument(stub, c.chaincode.Init)
+}
+
+func (c *InstrumentedChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	return c.instrument(stub, c.chaincode.Invoke)
+}
+
+// Drain rejects new invocations and waits for those in flight to finish or ctx to expire
+func (c *InstrumentedChaincode) Drain(ctx context.Context) error {
+	c.mu.Lock()
+	c.draining = true
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// begin registers an invocation, returning false once draining has started
+func (c *InstrumentedChaincode) begin() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.draining {
+		return false
+	}
+	c.inFlight.Add(1)
+
+	return true
+}
+
+func (c *InstrumentedChaincode) instrument(stub shim.ChaincodeStubInterface, next func(shim.ChaincodeStubInterface) pb.Response) pb.Response {
+	function, _ := stub.GetFunctionAndParameters()
+	if function == "" {
+		function = unknownFunction
+	}
+
+	label := function
+	if !c.functions[function] {
+		label = unknownFunction
+	}
+
+	callerMSP, err := cid.GetMSPID(stub)
+	if err != nil {
+		callerMSP = "unknown"
+	}
+
+	logger := c.logger.With(
+		slog.String("tx_id", stub.GetTxID()),
+		slog.String("channel", stub.GetChannelID()),
+		slog.String("function", function),
+		slog.String("caller_msp", callerMSP),
+	)
+
+	if !c.begin() {
+		logger.Warn("rejecting invocation, chaincode is shutting down")
+		return shim.Error("chaincode is shutting down, retry the transaction")
+	}
+	defer c.inFlight.Done()
+
+	logger.Debug("invocation started")
+
+	start := time.Now()
+	response := next(stub)
+	elapsed := time.Since(start)
+
+	failed := response.Status >= shim.ERRORTHRESHOLD
+	c.metrics.observe(label, elapsed, failed)
+
+	if failed {
+		logger.Error("invocation failed",
+			slog.Int("status", int(response.Status)),
+			slog.String("error", response.Message),
+			slog.Duration("duration", elapsed),
+		)
+	} else {
+		logger.Info("invocation completed",
+			slog.Int("status", int(response.Status)),
+			slog.Duration("duration", elapsed),
+		)
+	}
+
+	return response
+}
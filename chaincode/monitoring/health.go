@@ -0,0 +1,75 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server exposes /healthz, /readyz and /metrics on a side port next to the chaincode gRPC server
+type Server struct {
+	http   *http.Server
+	ready  atomic.Bool
+	logger *slog.Logger
+}
+
+func NewServer(address string, metrics *Metrics, logger *slog.Logger) *Server {
+	s := &Server{
+		logger: logger,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /readyz", s.handleReady)
+	mux.Handle("GET /metrics", promhttp.HandlerFor(metrics.registry, promhttp.HandlerOpts{}))
+
+	s.http = &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return s
+}
+
+// SetReady marks whether the chaincode server is accepting invocations
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+// Start serves in the background until Shutdown is called
+func (s *Server) Start() {
+	go func() {
+		s.logger.Info("starting monitoring server", slog.String("address", s.http.Addr))
+		if err := s.http.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("monitoring server stopped", slog.Any("error", err))
+		}
+	}()
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+// handleHealth reports the process is alive
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok\n"))
+}
+
+// handleReady reports whether the chaincode server is accepting invocations
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if !s.ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("not ready\n"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ready\n"))
+}
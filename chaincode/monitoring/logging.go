@@ -0,0 +1,31 @@
+package monitoring
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// NewLogger builds a levelled logger writing JSON, or logfmt-style text when format is "text"
+func NewLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	var logLevel slog.Level
+	if level != "" {
+		if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level [%s]: %w", level, err)
+		}
+	}
+
+	options := &slog.HandlerOptions{
+		Level: logLevel,
+	}
+
+	switch strings.ToLower(format) {
+	case "", "json":
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format [%s], expected json or text", format)
+	}
+}
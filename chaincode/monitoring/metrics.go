@@ -0,0 +1,69 @@
+package monitoring
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const (
+	statusSuccess = "success"
+	statusError   = "error"
+)
+
+// Metrics holds the Prometheus collectors recorded for each contract invocation
+type Metrics struct {
+	registry *prometheus.Registry
+
+	transactions *prometheus.CounterVec
+	errors       *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		transactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chaincode",
+			Name:      "transactions_total",
+			Help:      "Contract invocations by function and outcome.",
+		}, []string{"function", "status"}),
+
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chaincode",
+			Name:      "transaction_errors_total",
+			Help:      "Contract invocations that returned an error, by function.",
+		}, []string{"function"}),
+
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "chaincode",
+			Name:      "transaction_duration_seconds",
+			Help:      "Time spent executing contract invocations, by function.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"function"}),
+	}
+
+	m.registry.MustRegister(
+		m.transactions,
+		m.errors,
+		m.duration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// observe records one invocation of function
+func (m *Metrics) observe(function string, elapsed time.Duration, failed bool) {
+	status := statusSuccess
+	if failed {
+		status = statusError
+		m.errors.WithLabelValues(function).Inc()
+	}
+
+	m.transactions.WithLabelValues(function, status).Inc()
+	m.duration.WithLabelValues(function).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+const DocTypeCourse = "course"
+
+const courseSchemaVersion = 1
+
+type CourseRepo struct {
+	*repo[domain.Course]
+}
+
+func NewCourseRepo(stub shim.ChaincodeStubInterface) *CourseRepo {
+	return &CourseRepo{
+		repo: &repo[domain.Course]{
+			stub:          stub,
+			docType:       DocTypeCourse,
+			id:            func(c *domain.Course) string { return c.TrainingCode },
+			schemaVersion: courseSchemaVersion,
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Revision is one committed write of a document, Doc is nil for the write that deleted it
+type Revision[T any] struct {
+	TxID      string
+	Timestamp time.Time
+	Doc       *T
+}
+
+// History returns every revision of the document, oldest first. It reads the peer's history
+// database, so needs that enabled, and is only meaningful in evaluated transactions.
+func (r *repo[T]) History(id string) ([]Revision[T], error) {
+	key := r.Key(id)
+
+	iterator, err := r.stub.GetHistoryForKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %w", key, err)
+	}
+	defer iterator.Close()
+
+	var revisions []Revision[T]
+	for iterator.HasNext() {
+		modification, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history of %s: %w", key, err)
+		}
+
+		revision := Revision[T]{
+			TxID:      modification.GetTxId(),
+			Timestamp: modification.GetTimestamp().AsTime(),
+		}
+		if !modification.GetIsDelete() {
+			revision.Doc, err = r.decode(key, modification.GetValue())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	// Peers return revisions newest first in commit order. Timestamps are chosen by the submitting
+	// client, so the order is reversed rather than sorted on them.
+	slices.Reverse(revisions)
+
+	return revisions, nil
+}
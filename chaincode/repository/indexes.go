@@ -0,0 +1,362 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+)
+
+// IndexPage is the outcome of checking, or repairing, one page of a document type's indexes
+type IndexPage struct {
+	Scanned      int
+	Documents    int
+	IndexEntries int
+	// Missing and Orphaned are the problems found, a repair has already fixed them
+	Missing  []domain.IndexProblem
+	Orphaned []domain.IndexProblem
+	// Bookmark is the key to resume from, empty once every document and index entry has been scanned
+	Bookmark string
+}
+
+// OwnsBookmark reports whether a VerifyIndexes or RepairIndexes bookmark falls within this document type
+func (r *repo[T]) OwnsBookmark(bookmark string) bool {
+	if strings.HasPrefix(bookmark, r.Key("")) {
+		return true
+	}
+	for _, idx := range r.indexes {
+		if isIndexBookmark(idx.name, bookmark) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyIndexes reports documents missing index entries and index entries no document produces,
+// scanning at most pageSize documents and index entries from the bookmark
+func (r *repo[T]) VerifyIndexes(pageSize int, bookmark string) (*IndexPage, error) {
+	return r.scanIndexes(pageSize, bookmark, false)
+}
+
+// RepairIndexes writes missing index entries and deletes orphaned ones, scanning at most pageSize
+// documents and index entries from the bookmark
+func (r *repo[T]) RepairIndexes(pageSize int, bookmark string) (*IndexPage, error) {
+	return r.scanIndexes(pageSize, bookmark, true)
+}
+
+// scanIndexes scans documents first, so with repair every entry they need exists, then sweeps each
+// index for entries no document produces
+func (r *repo[T]) scanIndexes(pageSize int, bookmark string, repair bool) (*IndexPage, error) {
+	page := &IndexPage{
+		Missing:  []domain.IndexProblem{},
+		Orphaned: []domain.IndexProblem{},
+	}
+	if len(r.indexes) == 0 {
+		return page, nil
+	}
+
+	phase := 0
+	if bookmark != "" {
+		phase = -1
+		if strings.HasPrefix(bookmark, r.Key("")) {
+			phase = 0
+		}
+		for i, idx := range r.indexes {
+			if isIndexBookmark(idx.name, bookmark) {
+				phase = i + 1
+			}
+		}
+		if phase == -1 {
+			return nil, fmt.Errorf("bookmark [%s] does not belong to %s", bookmark, r.docType)
+		}
+	}
+
+	if phase == 0 {
+		if err := r.scanDocuments(page, pageSize, bookmark, repair); err != nil {
+			return nil, err
+		}
+		if page.Bookmark != "" {
+			return page, nil
+		}
+		bookmark = ""
+		phase = 1
+	}
+
+	for i := phase - 1; i < len(r.indexes); i++ {
+		if page.Scanned == pageSize {
+			page.Bookmark = indexBookmarkPrefix(r.indexes[i].name)
+			return page, nil
+		}
+
+		if err := r.scanIndex(page, r.indexes[i], pageSize, bookmark, repair); err != nil {
+			return nil, err
+		}
+		if page.Bookmark != "" {
+			return page, nil
+		}
+		bookmark = ""
+	}
+
+	return page, nil
+}
+
+// scanDocuments finds the index entries missing for the documents from startKey, writing them
+// when repairing
+func (r *repo[T]) scanDocuments(page *IndexPage, pageSize int, startKey string, repair bool) error {
+	if startKey == "" {
+		startKey = r.Key("")
+	}
+
+	// As with Migrate, the range is bounded by hand as paginated queries are not allowed in submit transactions
+	iterator, err := r.stub.GetStateByRange(startKey, r.docType+";")
+	if err != nil {
+		return fmt.Errorf("failed to query %s range: %w", r.docType, err)
+	}
+
+	var missing []string
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			iterator.Close()
+			return fmt.Errorf("failed to iterate %s range: %w", r.docType, err)
+		}
+
+		if page.Scanned == pageSize {
+			page.Bookmark = response.Key
+			break
+		}
+		page.Scanned++
+		page.Documents++
+
+		doc, err := r.decode(response.Key, response.Value)
+		if err != nil {
+			iterator.Close()
+			return err
+		}
+
+		for _, idx := range r.indexes {
+			indexKey, err := r.indexKey(idx, doc)
+			if err != nil {
+				iterator.Close()
+				return err
+			}
+
+			value, err := r.stub.GetState(indexKey)
+			if err != nil {
+				iterator.Close()
+				return fmt.Errorf("failed to read index %s entry: %w", idx.name, err)
+			}
+			if value == nil {
+				missing = append(missing, indexKey)
+				page.Missing = append(page.Missing, domain.IndexProblem{
+					Index:      idx.name,
+					Attributes: idx.attributes(doc),
+					DocumentID: r.id(doc),
+					Reason:     domain.IndexProblemMissingEntry,
+				})
+			}
+		}
+	}
+	iterator.Close()
+
+	if !repair {
+		return nil
+	}
+	for _, indexKey := range missing {
+		if err := r.stub.PutState(indexKey, indexMarker); err != nil {
+			return fmt.Errorf("failed to put index entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// scanIndex finds the entries of idx from the bookmark that no document produces, deleting them
+// when repairing
+func (r *repo[T]) scanIndex(page *IndexPage, idx index[T], pageSize int, bookmark string, repair bool) error {
+	start, err := indexBookmarkAttributes(idx, bookmark)
+	if err != nil {
+		return err
+	}
+
+	var orphaned []string
+	err = r.eachIndexEntry(idx, start, func(entry indexEntry) (bool, error) {
+		if page.Scanned == pageSize {
+			page.Bookmark = indexBookmark(idx.name, entry.attributes)
+			return false, nil
+		}
+		page.Scanned++
+		page.IndexEntries++
+
+		doc, err := r.Get(entry.documentID())
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return false, err
+		}
+
+		reason := domain.IndexProblemMissingDocument
+		if doc != nil {
+			indexKey, err := r.indexKey(idx, doc)
+			if err != nil {
+				return false, err
+			}
+			if indexKey == entry.key {
+				return true, nil
+			}
+			reason = domain.IndexProblemStaleEntry
+		}
+
+		orphaned = append(orphaned, entry.key)
+		page.Orphaned = append(page.Orphaned, domain.IndexProblem{
+			Index:      idx.name,
+			Attributes: entry.attributes,
+			DocumentID: entry.documentID(),
+			Reason:     reason,
+		})
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !repair {
+		return nil
+	}
+	for _, indexKey := range orphaned {
+		if err := r.stub.DelState(indexKey); err != nil {
+			return fmt.Errorf("failed to delete index %s entry: %w", idx.name, err)
+		}
+	}
+
+	return nil
+}
+
+type indexEntry struct {
+	key        string
+	attributes []string
+}
+
+// documentID is the last attribute of an index entry, see index
+func (e indexEntry) documentID() string {
+	if len(e.attributes) == 0 {
+		return ""
+	}
+	return e.attributes[len(e.attributes)-1]
+}
+
+// eachIndexEntry calls visit with the entries of idx in key order, from the entry with the start
+// attributes or the head of the index when start is empty, until visit returns false.
+//
+// Composite keys cannot be range queried, and paginated queries are not allowed in submit
+// transactions, so a resumed iteration first queries the start entry's group, the entries sharing
+// all of its attributes but the document ID, then widens the partial key one attribute at a time to
+// reach the groups after it. Only the entries before start within the group being widened are
+// passed over, so the head of the index is only read again once a page crosses into a new first
+// attribute. Closing each iterator as soon as visit stops keeps the read set to the page.
+func (r *repo[T]) eachIndexEntry(idx index[T], start []string, visit func(indexEntry) (bool, error)) error {
+	if len(start) == 0 {
+		_, err := r.eachIndexEntryFrom(idx, nil, "", visit)
+		return err
+	}
+
+	fromKey, err := r.stub.CreateCompositeKey(idx.name, start)
+	if err != nil {
+		return fmt.Errorf("failed to create index %s key: %w", idx.name, err)
+	}
+
+	for level := len(start) - 1; level >= 0; level-- {
+		if level < len(start)-1 {
+			// Every entry of the narrower group start[:level+1] has been visited
+			groupKey, err := r.stub.CreateCompositeKey(idx.name, start[:level+1])
+			if err != nil {
+				return fmt.Errorf("failed to create index %s key: %w", idx.name, err)
+			}
+			fromKey = groupKey + string(utf8.MaxRune)
+		}
+
+		more, err := r.eachIndexEntryFrom(idx, start[:level], fromKey, visit)
+		if err != nil || !more {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// eachIndexEntryFrom visits the entries starting with the prefix attributes from fromKey, reporting
+// whether visit wants more
+func (r *repo[T]) eachIndexEntryFrom(idx index[T], prefix []string, fromKey string, visit func(indexEntry) (bool, error)) (bool, error) {
+	iterator, err := r.stub.GetStateByPartialCompositeKey(idx.name, prefix)
+	if err != nil {
+		return false, fmt.Errorf("failed to query index %s: %w", idx.name, err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return false, fmt.Errorf("failed to iterate index %s: %w", idx.name, err)
+		}
+		if response.Key < fromKey {
+			continue
+		}
+
+		_, attributes, err := r.stub.SplitCompositeKey(response.Key)
+		if err != nil {
+			return false, fmt.Errorf("failed to split index %s key: %w", idx.name, err)
+		}
+
+		more, err := visit(indexEntry{key: response.Key, attributes: attributes})
+		if err != nil || !more {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// Index entries are composite keys, which hold U+0000 separators, so VerifyIndexes and RepairIndexes bookmarks name
+// the index and the entry's escaped attributes instead, e.g. index:personnel_byCampus/Luna/P-0001
+
+// indexBookmarkPrefix is the bookmark of the head of the index
+func indexBookmarkPrefix(name string) string {
+	return "index:" + name
+}
+
+// isIndexBookmark reports whether bookmark resumes the index called name
+func isIndexBookmark(name, bookmark string) bool {
+	prefix := indexBookmarkPrefix(name)
+	return bookmark == prefix || strings.HasPrefix(bookmark, prefix+"/")
+}
+
+// indexBookmark is the bookmark resuming the index at the entry with attributes
+func indexBookmark(name string, attributes []string) string {
+	escaped := make([]string, len(attributes))
+	for i, attribute := range attributes {
+		escaped[i] = url.PathEscape(attribute)
+	}
+	return indexBookmarkPrefix(name) + "/" + strings.Join(escaped, "/")
+}
+
+// indexBookmarkAttributes are the attributes of the entry an index bookmark resumes from, empty for
+// the head of the index
+func indexBookmarkAttributes[T any](idx index[T], bookmark string) ([]string, error) {
+	rest, found := strings.CutPrefix(bookmark, indexBookmarkPrefix(idx.name)+"/")
+	if !found {
+		return nil, nil
+	}
+
+	escaped := strings.Split(rest, "/")
+	attributes := make([]string, len(escaped))
+	for i, attribute := range escaped {
+		unescaped, err := url.PathUnescape(attribute)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bookmark [%s]: %w", bookmark, err)
+		}
+		attributes[i] = unescaped
+	}
+
+	return attributes, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"fmt"
+)
+
+// MigrationPage is the outcome of migrating one page of a document type
+type MigrationPage struct {
+	Scanned  int
+	Migrated int
+	// Bookmark is the key to resume from, empty once the last document has been scanned
+	Bookmark string
+}
+
+// Migrate rewrites the documents stored at fromVersion with the current schema version, scanning
+// at most pageSize documents from the bookmark key (or the first document when empty).
+// Paginated range queries are not allowed in submit transactions, so the page is bounded by hand
+// and the key following it is returned as the bookmark.
+func (r *repo[T]) Migrate(fromVersion, pageSize int, bookmark string) (*MigrationPage, error) {
+	startKey := bookmark
+	if startKey == "" {
+		startKey = r.Key("")
+	}
+
+	iterator, err := r.stub.GetStateByRange(startKey, r.docType+";")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s range: %w", r.docType, err)
+	}
+
+	page := &MigrationPage{}
+	var pending []*T
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			iterator.Close()
+			return nil, fmt.Errorf("failed to iterate %s range: %w", r.docType, err)
+		}
+
+		if page.Scanned == pageSize {
+			page.Bookmark = response.Key
+			break
+		}
+		page.Scanned++
+
+		version, err := storedVersion(response.Value)
+		if err != nil {
+			iterator.Close()
+			return nil, fmt.Errorf("failed to read schema version of %s: %w", response.Key, err)
+		}
+		if version != fromVersion || version == r.schemaVersion {
+			continue
+		}
+
+		doc, err := r.decode(response.Key, response.Value)
+		if err != nil {
+			iterator.Close()
+			return nil, err
+		}
+		pending = append(pending, doc)
+	}
+	iterator.Close()
+
+	// Rewritten once the iterator is closed so no write interleaves with the range read
+	for _, doc := range pending {
+		if err := r.Put(doc); err != nil {
+			return nil, err
+		}
+	}
+	page.Migrated = len(pending)
+
+	return page, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+const DocTypePersonnel = "personnel"
+
+// personnelSchemaVersion is bumped whenever the stored shape changes, version 1 added the schemaVersion
+// marker. Version 2 keeps the shape, migrating to it rewrites every member so each gains its campus
+// index entry.
+const personnelSchemaVersion = 2
+
+// IndexPersonnelByCampus allows "Who is at Engineering?", campus rosters and rank distribution.
+// Pattern `personnel_byCampus~Engineering~SF-12345`. Personnel enrolled before it was added gain
+// their entry when AdminContract:MigrateState upgrades them to schema version 2.
+const IndexPersonnelByCampus = "personnel_byCampus"
+
+type PersonnelRepo struct {
+	*repo[domain.Personnel]
+}
+
+func NewPersonnelRepo(stub shim.ChaincodeStubInterface) *PersonnelRepo {
+	return &PersonnelRepo{
+		repo: &repo[domain.Personnel]{
+			stub:          stub,
+			docType:       DocTypePersonnel,
+			id:            func(p *domain.Personnel) string { return p.PersonnelID },
+			schemaVersion: personnelSchemaVersion,
+			indexes: []index[domain.Personnel]{
+				{
+					name: IndexPersonnelByCampus,
+					attributes: func(p *domain.Personnel) []string {
+						return []string{p.Campus, p.PersonnelID}
+					},
+				},
+			},
+		},
+	}
+}
+
+// ListByCampus returns the campus's personnel ordered by ID
+func (r *PersonnelRepo) ListByCampus(campus string) ([]*domain.Personnel, error) {
+	return r.findByIndex(IndexPersonnelByCampus, campus)
+}
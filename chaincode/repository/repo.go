@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// indexMarker is the value stored against composite index keys, the key itself carries the data
+var indexMarker = []byte{0x00}
+
+// index is a composite-key secondary index, attributes returns the key parts for a document with
+// the document ID last so every entry is unique
+type index[T any] struct {
+	name       string
+	attributes func(*T) []string
+}
+
+// migration upgrades the stored fields of a document from one schema version to the next
+type migration func(fields map[string]json.RawMessage) error
+
+// repo owns the key format, serialization and index maintenance for one document type.
+// Every write goes through Put or Delete so a document and its index entries never drift apart.
+type repo[T any] struct {
+	stub    shim.ChaincodeStubInterface
+	docType string
+	id      func(*T) string
+	indexes []index[T]
+
+	// schemaVersion is stamped on every stored document alongside its fields, it is not part of
+	// the domain type. migrations is keyed by the version being upgraded from, a version with no
+	// entry only needs the new version marker.
+	schemaVersion int
+	migrations    map[int]migration
+}
+
+// DocType is the prefix of the document's world state keys
+func (r *repo[T]) DocType() string {
+	return r.docType
+}
+
+// SchemaVersion is the version written by Put, older documents are upgraded as they are read
+func (r *repo[T]) SchemaVersion() int {
+	return r.schemaVersion
+}
+
+// Key returns the world state key of the document with the given ID
+func (r *repo[T]) Key(id string) string {
+	return fmt.Sprintf("%s:%s", r.docType, id)
+}
+
+// Get returns ErrNotFound when the document does not exist
+func (r *repo[T]) Get(id string) (*T, error) {
+	key := r.Key(id)
+
+	valueBytes, err := r.stub.GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from world state: %w", key, err)
+	}
+	if valueBytes == nil {
+		return nil, fmt.Errorf("%s %s: %w", r.docType, id, ErrNotFound)
+	}
+
+	return r.decode(key, valueBytes)
+}
+
+func (r *repo[T]) Exists(id string) (bool, error) {
+	key := r.Key(id)
+
+	valueBytes, err := r.stub.GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s from world state: %w", key, err)
+	}
+
+	return valueBytes != nil, nil
+}
+
+// Put writes the document and its index entries, removing entries left stale by changed attributes
+func (r *repo[T]) Put(doc *T) error {
+	id := r.id(doc)
+	key := r.Key(id)
+
+	existing, err := r.Get(id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	valueBytes, err := r.encode(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+
+	if err := r.stub.PutState(key, valueBytes); err != nil {
+		return fmt.Errorf("failed to put %s state: %w", key, err)
+	}
+
+	for _, idx := range r.indexes {
+		newKey, err := r.indexKey(idx, doc)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			oldKey, err := r.indexKey(idx, existing)
+			if err != nil {
+				return err
+			}
+			if oldKey != newKey {
+				if err := r.stub.DelState(oldKey); err != nil {
+					return fmt.Errorf("failed to delete index %s entry: %w", idx.name, err)
+				}
+			}
+		}
+
+		// Written even when unchanged, so documents stored before an index was added gain their
+		// entry the next time they are saved
+		if err := r.stub.PutState(newKey, indexMarker); err != nil {
+			return fmt.Errorf("failed to put index %s entry: %w", idx.name, err)
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the document and all of its index entries
+func (r *repo[T]) Delete(id string) error {
+	doc, err := r.Get(id)
+	if err != nil {
+		return err
+	}
+
+	for _, idx := range r.indexes {
+		indexKey, err := r.indexKey(idx, doc)
+		if err != nil {
+			return err
+		}
+		if err := r.stub.DelState(indexKey); err != nil {
+			return fmt.Errorf("failed to delete index %s entry: %w", idx.name, err)
+		}
+	}
+
+	if err := r.stub.DelState(r.Key(id)); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", r.Key(id), err)
+	}
+
+	return nil
+}
+
+// List returns every document of this type in key order
+func (r *repo[T]) List() ([]*T, error) {
+	// ';' sorts directly after ':' so this range covers every `docType:` key
+	iterator, err := r.stub.GetStateByRange(r.Key(""), r.docType+";")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s range: %w", r.docType, err)
+	}
+	defer iterator.Close()
+
+	docs := []*T{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate %s range: %w", r.docType, err)
+		}
+
+		doc, err := r.decode(response.Key, response.Value)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// findByIndex returns the documents whose index entries start with the given attributes.
+// Index entries pointing at missing documents are logged and skipped.
+func (r *repo[T]) findByIndex(indexName string, attributes ...string) ([]*T, error) {
+	iterator, err := r.stub.GetStateByPartialCompositeKey(indexName, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index %s: %w", indexName, err)
+	}
+	defer iterator.Close()
+
+	docs := []*T{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate index %s: %w", indexName, err)
+		}
+
+		_, parts, err := r.stub.SplitCompositeKey(response.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split index %s key: %w", indexName, err)
+		}
+		if len(parts) == 0 {
+			continue // skip invalid keys
+		}
+
+		doc, err := r.Get(parts[len(parts)-1])
+		if errors.Is(err, ErrNotFound) {
+			// Reported and removed by AdminContract:VerifyIndexes and RepairIndexes
+			slog.Warn("index entry points at a missing document", slog.String("index", indexName), slog.Any("attributes", parts))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+func (r *repo[T]) indexKey(idx index[T], doc *T) (string, error) {
+	key, err := r.stub.CreateCompositeKey(idx.name, idx.attributes(doc))
+	if err != nil {
+		return "", fmt.Errorf("failed to create index %s key: %w", idx.name, err)
+	}
+	return key, nil
+}
+
+// encode marshals a document for storage, adding the schema version marker to its fields
+func (r *repo[T]) encode(doc *T) ([]byte, error) {
+	docBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(docBytes, &fields); err != nil {
+		return nil, err
+	}
+	fields["schemaVersion"] = json.RawMessage(strconv.Itoa(r.schemaVersion))
+
+	return json.Marshal(fields)
+}
+
+// decode unmarshals a stored document, first upgrading it if it was written with an older schema
+func (r *repo[T]) decode(key string, valueBytes []byte) (*T, error) {
+	version, err := storedVersion(valueBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version of %s: %w", key, err)
+	}
+	if version > r.schemaVersion {
+		return nil, fmt.Errorf("%s has schema version %d, newer than the supported version %d", key, version, r.schemaVersion)
+	}
+
+	if version < r.schemaVersion {
+		valueBytes, err = r.upgrade(version, valueBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upgrade %s from schema version %d: %w", key, version, err)
+		}
+	}
+
+	var doc T
+	if err := json.Unmarshal(valueBytes, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", key, err)
+	}
+	return &doc, nil
+}
+
+// upgrade applies each migration between version and the current schema version in turn
+func (r *repo[T]) upgrade(version int, valueBytes []byte) ([]byte, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(valueBytes, &fields); err != nil {
+		return nil, err
+	}
+
+	for ; version < r.schemaVersion; version++ {
+		if migrate, ok := r.migrations[version]; ok {
+			if err := migrate(fields); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fields["schemaVersion"] = json.RawMessage(strconv.Itoa(r.schemaVersion))
+
+	return json.Marshal(fields)
+}
+
+// storedVersion reads the schema version of a stored document, documents written before
+// versioning was introduced have none and are version 0
+func storedVersion(valueBytes []byte) (int, error) {
+	var header struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(valueBytes, &header); err != nil {
+		return 0, err
+	}
+	return header.SchemaVersion, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"github.com/chrisarmitage/hlf-chaincode-starfleet-personnel/domain"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+const DocTypeTraining = "training"
+
+// trainingSchemaVersion 1 is the original record shape with a schemaVersion marker
+const trainingSchemaVersion = 1
+
+const (
+	// IndexTrainingByPersonnel allows "All training for this personnel", "Ordered history"
+	// Pattern `training_byPersonnel~SF-12345~2024-01-01T12:00:00Z~TR-987`
+	IndexTrainingByPersonnel = "training_byPersonnel"
+	// IndexTrainingByCode allows "Who has completed ENG-WARP-201?", "Promotion validation"
+	// Pattern `training_byCode~ENG-WARP-201~SF-12345~TR-987`
+	IndexTrainingByCode = "training_byCode"
+)
+
+type TrainingRepo struct {
+	*repo[domain.Training]
+}
+
+func NewTrainingRepo(stub shim.ChaincodeStubInterface) *TrainingRepo {
+	return &TrainingRepo{
+		repo: &repo[domain.Training]{
+			stub:          stub,
+			docType:       DocTypeTraining,
+			id:            func(t *domain.Training) string { return t.RecordID },
+			schemaVersion: trainingSchemaVersion,
+			indexes: []index[domain.Training]{
+				{
+					name: IndexTrainingByPersonnel,
+					attributes: func(t *domain.Training) []string {
+						return []string{t.PersonnelID, t.CompletedAt, t.RecordID}
+					},
+				},
+				{
+					name: IndexTrainingByCode,
+					attributes: func(t *domain.Training) []string {
+						return []string{t.TrainingCode, t.PersonnelID, t.RecordID}
+					},
+				},
+			},
+		},
+	}
+}
+
+// ListByPersonnel returns the personnel's training ordered by completion time
+func (r *TrainingRepo) ListByPersonnel(personnelID string) ([]*domain.Training, error) {
+	return r.findByIndex(IndexTrainingByPersonnel, personnelID)
+}
+
+// ListByCode returns every record for the training code, optionally limited to one personnel
+func (r *TrainingRepo) ListByCode(trainingCode string, personnelID ...string) ([]*domain.Training, error) {
+	return r.findByIndex(IndexTrainingByCode, append([]string{trainingCode}, personnelID...)...)
+}
+
+// FindCompleted returns the personnel's completed record for the training code, or nil if there is none
+func (r *TrainingRepo) FindCompleted(personnelID, trainingCode string) (*domain.Training, error) {
+	records, err := r.ListByCode(trainingCode, personnelID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, training := range records {
+		if training.Status == domain.TrainingStatusCompleted {
+			return training, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+var errStreamClosed = errors.New("chaincode stream is closed")
+
+// maxMessageSize matches the peer's default gRPC message limits, as shim.ChaincodeServer uses
+const maxMessageSize = 100 * 1024 * 1024
+
+// chaincodeServer serves the shim's Connect stream like shim.ChaincodeServer.Start, but binds its
+// listener up front so readiness is only reported once the address is held, and tracks the
+// responses owed to the peer so shutdown can wait until they have been sent
+type chaincodeServer struct {
+	listener net.Listener
+	grpc     *grpc.Server
+	shim     *shim.ChaincodeServer
+
+	mu       sync.Mutex
+	pending  int
+	flushed  chan struct{}
+	closing  chan struct{}
+	stopOnce sync.Once
+}
+
+// newChaincodeServer binds address and registers the chaincode, it does not serve until Serve is called
+func newChaincodeServer(ccid, address string, tlsProps shim.TLSProperties, chaincode shim.Chaincode) (*chaincodeServer, error) {
+	if ccid == "" {
+		return nil, errors.New("ccid must be specified")
+	}
+
+	options := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    1 * time.Minute,
+			Timeout: 20 * time.Second,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             1 * time.Minute,
+			PermitWithoutStream: true,
+		}),
+		grpc.MaxSendMsgSize(maxMessageSize),
+		grpc.MaxRecvMsgSize(maxMessageSize),
+		grpc.ConnectionTimeout(5 * time.Second),
+	}
+
+	if !tlsProps.Disabled {
+		tlsConfig, err := loadTLSConfig(tlsProps)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("error listening on %s: %w", address, err)
+	}
+
+	s := &chaincodeServer{
+		listener: listener,
+		grpc:     grpc.NewServer(options...),
+		shim: &shim.ChaincodeServer{
+			CCID:    ccid,
+			Address: address,
+			CC:      chaincode,
+		},
+		flushed: make(chan struct{}, 1),
+		closing: make(chan struct{}),
+	}
+	pb.RegisterChaincodeServer(s.grpc, s)
+
+	return s, nil
+}
+
+// Addr is the address the server is bound to
+func (s *chaincodeServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve blocks serving peer connections until the server is stopped
+func (s *chaincodeServer) Serve() error {
+	return s.grpc.Serve(s.listener)
+}
+
+// Connect hands the peer's stream to the shim. Once shutdown has flushed every response the shim
+// reads the end of the stream, and Connect returns after it has so gRPC can close the stream
+// behind the messages already sent.
+func (s *chaincodeServer) Connect(stream pb.Chaincode_ConnectServer) error {
+	tracked := &trackedStream{Chaincode_ConnectServer: stream, server: s, owed: map[string]bool{}}
+	defer tracked.close()
+
+	err := s.shim.Connect(tracked)
+
+	select {
+	case <-s.closing:
+		return nil
+	default:
+		return err
+	}
+}
+
+// Shutdown waits for every response owed to the peer to be sent, then stops the server. Once ctx
+// expires outstanding responses are abandoned and connections are closed.
+func (s *chaincodeServer) Shutdown(ctx context.Context) error {
+	err := s.awaitResponses(ctx)
+
+	s.stopOnce.Do(func() { close(s.closing) })
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpc.Stop()
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	return err
+}
+
+// Stop closes the listener and every open peer connection without waiting for responses
+func (s *chaincodeServer) Stop() {
+	s.stopOnce.Do(func() { close(s.closing) })
+	s.grpc.Stop()
+}
+
+func (s *chaincodeServer) awaitResponses(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		pending := s.pending
+		s.mu.Unlock()
+
+		if pending == 0 {
+			return nil
+		}
+
+		select {
+		case <-s.flushed:
+		case <-ctx.Done():
+			return fmt.Errorf("%d responses were not sent: %w", pending, ctx.Err())
+		}
+	}
+}
+
+func (s *chaincodeServer) addPending(delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending += delta
+	if s.pending == 0 {
+		select {
+		case s.flushed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// trackedStream counts the Init and Transaction requests received on a stream until the shim sends
+// their COMPLETED or ERROR response. It ends the stream for the shim when the server is closing and
+// refuses sends once Connect has returned, as the shim answers transactions from goroutines of its own.
+type trackedStream struct {
+	pb.Chaincode_ConnectServer
+	server *chaincodeServer
+
+	mu   sync.Mutex
+	owed map[string]bool
+
+	sendMu sync.RWMutex
+	closed bool
+}
+
+type received struct {
+	message *pb.ChaincodeMessage
+	err     error
+}
+
+func (t *trackedStream) Recv() (*pb.ChaincodeMessage, error) {
+	// The stream cannot be interrupted from the handler, so the read runs apart and is abandoned
+	// when the server closes. It returns as soon as gRPC ends the stream behind Connect.
+	result := make(chan received, 1)
+	go func() {
+		message, err := t.Chaincode_ConnectServer.Recv()
+		result <- received{message, err}
+	}()
+
+	var r received
+	select {
+	case r = <-result:
+	case <-t.server.closing:
+		return nil, io.EOF
+	}
+
+	if r.err == nil {
+		switch r.message.GetType() {
+		case pb.ChaincodeMessage_INIT, pb.ChaincodeMessage_TRANSACTION:
+			t.settle(r.message, true)
+		}
+	}
+
+	return r.message, r.err
+}
+
+func (t *trackedStream) Send(message *pb.ChaincodeMessage) error {
+	t.sendMu.RLock()
+	defer t.sendMu.RUnlock()
+
+	if t.closed {
+		return errStreamClosed
+	}
+	err := t.Chaincode_ConnectServer.Send(message)
+
+	switch message.GetType() {
+	case pb.ChaincodeMessage_COMPLETED, pb.ChaincodeMessage_ERROR:
+		t.settle(message, false)
+	}
+
+	return err
+}
+
+// settle records a response as owed, or as sent
+func (t *trackedStream) settle(message *pb.ChaincodeMessage, owed bool) {
+	key := message.GetChannelId() + "/" + message.GetTxid()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.owed[key] == owed {
+		return
+	}
+
+	if owed {
+		t.owed[key] = true
+		t.server.addPending(1)
+	} else {
+		delete(t.owed, key)
+		t.server.addPending(-1)
+	}
+}
+
+// close stops further sends and gives up on the responses still owed, the peer can no longer
+// receive them once Connect has returned
+func (t *trackedStream) close() {
+	t.sendMu.Lock()
+	t.closed = true
+	t.sendMu.Unlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.owed) > 0 {
+		t.server.addPending(-len(t.owed))
+		t.owed = map[string]bool{}
+	}
+}
+
+// loadTLSConfig builds the server TLS configuration the shim would, requiring a client certificate
+// issued by the client CA when one is set
+func loadTLSConfig(tlsProps shim.TLSProperties) (*tls.Config, error) {
+	if tlsProps.Key == nil || tlsProps.Cert == nil {
+		return nil, errors.New("TLS key and certificate are required when TLS is enabled")
+	}
+
+	certificate, err := tls.X509KeyPair(tlsProps.Cert, tlsProps.Key)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing TLS key pair: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:             tls.VersionTLS12,
+		Certificates:           []tls.Certificate{certificate},
+		SessionTicketsDisabled: true,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+		},
+	}
+
+	if len(tlsProps.ClientCACerts) > 0 {
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(tlsProps.ClientCACerts) {
+			return nil, errors.New("error parsing TLS client CA certificate")
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+// MigrationResult reports one page of AdminContract:MigrateState
+type MigrationResult struct {
+	FromVersion int `json:"fromVersion"`
+	Scanned     int `json:"scanned"`
+	Migrated    int `json:"migrated"`
+	// Bookmark is passed to the next call to resume the migration, empty once Done
+	Bookmark string `json:"bookmark"`
+	Done     bool   `json:"done"`
+}
+
+// IndexReport reports one page of AdminContract:VerifyIndexes
+type IndexReport struct {
+	Documents    int `json:"documents"`
+	IndexEntries int `json:"indexEntries"`
+	// Orphaned lists index entries pointing at a missing document, or at a document whose
+	// attributes no longer produce that entry
+	Orphaned []IndexProblem `json:"orphaned"`
+	// Missing lists index entries a document should have but does not
+	Missing []IndexProblem `json:"missing"`
+	// Consistent is true when the page found no problems
+	Consistent bool `json:"consistent"`
+	// Bookmark is passed to the next call to resume the check, empty once Done
+	Bookmark string `json:"bookmark"`
+	Done     bool   `json:"done"`
+}
+
+type IndexProblem struct {
+	Index      string   `json:"index"`
+	Attributes []string `json:"attributes"`
+	DocumentID string   `json:"documentID"`
+	Reason     string   `json:"reason"`
+}
+
+// IndexRepairResult reports one page of AdminContract:RepairIndexes
+type IndexRepairResult struct {
+	Scanned int `json:"scanned"`
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	// Bookmark is passed to the next call to resume the repair, empty once Done
+	Bookmark string `json:"bookmark"`
+	Done     bool   `json:"done"`
+}
+
+const (
+	IndexProblemMissingEntry    = "missing index entry"
+	IndexProblemMissingDocument = "document does not exist"
+	IndexProblemStaleEntry      = "document attributes do not match"
+)
@@ -0,0 +1,40 @@
+package domain
+
+// CadetEnrolment is one entry of PersonnelContract:EnrollCadetsBatch
+type CadetEnrolment struct {
+	PersonnelID string `json:"personnelID"`
+	Name        string `json:"name"`
+	Campus      string `json:"campus"`
+}
+
+// TrainingCompletion is one entry of TrainingContract:CompleteTrainingBatch
+type TrainingCompletion struct {
+	RecordID     string `json:"recordID"`
+	PersonnelID  string `json:"personnelID"`
+	Campus       string `json:"campus"`
+	TrainingCode string `json:"trainingCode"`
+	CompletedAt  string `json:"completedAt"`
+	IssuedBy     string `json:"issuedBy"`
+}
+
+// BatchEntryResult reports what happened to one entry of a batch, in the order submitted
+type BatchEntryResult struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty" metadata:",optional"`
+}
+
+// BatchResult is returned by the batch transactions. An all-or-nothing batch such as
+// EnrollCadetsBatch writes nothing when any entry is rejected, reporting Committed false and the
+// valid entries as skipped. CompleteTrainingBatch writes every valid entry and is always committed.
+type BatchResult struct {
+	Committed bool               `json:"committed"`
+	Results   []BatchEntryResult `json:"results"`
+}
+
+const (
+	BatchEntryWritten  = "written"
+	BatchEntryRejected = "rejected"
+	BatchEntrySkipped  = "skipped"
+)
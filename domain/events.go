@@ -0,0 +1,14 @@
+package domain
+
+// Chaincode event names. Fabric allows one event per transaction, so the batch transactions emit
+// a single event carrying every record they wrote.
+const (
+	// EventCadetEnrolled carries the enrolled Personnel
+	EventCadetEnrolled = "CadetEnrolled"
+	// EventCadetsEnrolled carries a []Personnel written by EnrollCadetsBatch
+	EventCadetsEnrolled = "CadetsEnrolled"
+	// EventTrainingCompleted carries the completed Training
+	EventTrainingCompleted = "TrainingCompleted"
+	// EventTrainingsCompleted carries a []Training written by CompleteTrainingBatch
+	EventTrainingsCompleted = "TrainingsCompleted"
+)
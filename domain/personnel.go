@@ -18,6 +18,13 @@ type Training struct {
 	Status       string `json:"status"`
 }
 
+type Course struct {
+	TrainingCode string `json:"trainingCode"`
+	Title        string `json:"title"`
+	Campus       string `json:"campus"`
+	Description  string `json:"description"`
+}
+
 const (
 	PersonnelRankCadet = "Cadet"
 
@@ -0,0 +1,35 @@
+package domain
+
+// CompletionReport is the result of ReportContract:GetCompletionStats. TrainingCode, From and To
+// echo the filters, empty when not applied.
+type CompletionReport struct {
+	TrainingCode string `json:"trainingCode"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Completions  int    `json:"completions"`
+	// Personnel counts distinct personnel, each code is completed once but one member may complete
+	// several codes
+	Personnel int               `json:"personnel"`
+	Counts    []CompletionCount `json:"counts"`
+}
+
+// CompletionCount is the completions of one training code at one campus
+type CompletionCount struct {
+	TrainingCode string `json:"trainingCode"`
+	Campus       string `json:"campus"`
+	Completions  int    `json:"completions"`
+	Personnel    int    `json:"personnel"`
+}
+
+// RankDistribution is the result of ReportContract:GetRankDistribution, Campus is empty for the whole academy
+type RankDistribution struct {
+	Campus string      `json:"campus"`
+	Total  int         `json:"total"`
+	Ranks  []RankCount `json:"ranks"`
+}
+
+type RankCount struct {
+	Rank   string `json:"rank"`
+	Total  int    `json:"total"`
+	Active int    `json:"active"`
+}
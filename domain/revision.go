@@ -0,0 +1,9 @@
+package domain
+
+// TrainingRevision is a training record with the transaction that last wrote it
+type TrainingRevision struct {
+	Training      *Training `json:"training"`
+	TransactionID string    `json:"transactionID"`
+	// Timestamp is the writing transaction's proposal time, RFC3339
+	Timestamp string `json:"timestamp"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+// Transcript is a member of personnel's academic record. Every line carries the ID of the
+// transaction that wrote it, so it can be audited against the ledger.
+type Transcript struct {
+	Personnel *Personnel `json:"personnel"`
+	// TransactionID wrote the current personnel record
+	TransactionID string           `json:"transactionID"`
+	StatusChanges []StatusChange   `json:"statusChanges"`
+	Training      []TranscriptLine `json:"training"`
+}
+
+// StatusChange is a transaction that set a new rank or status, including the enrolment
+type StatusChange struct {
+	Rank          string `json:"rank"`
+	Status        string `json:"status"`
+	ChangedAt     string `json:"changedAt"`
+	TransactionID string `json:"transactionID"`
+}
+
+// TranscriptLine is one training record, in completion order
+type TranscriptLine struct {
+	RecordID     string `json:"recordID"`
+	TrainingCode string `json:"trainingCode"`
+	// CourseTitle is empty when the training code is not in the catalogue
+	CourseTitle   string `json:"courseTitle"`
+	Campus        string `json:"campus"`
+	CompletedAt   string `json:"completedAt"`
+	IssuedBy      string `json:"issuedBy"`
+	Status        string `json:"status"`
+	TransactionID string `json:"transactionID"`
+}